@@ -0,0 +1,311 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tokenExpiryLeeway is how long before it expires an OAuth2 token is
+// replaced, so it does not expire while a request is in flight.
+const tokenExpiryLeeway = 30 * time.Second
+
+// Authenticator adds credentials to each request the client sends.
+// Implementations must be safe for concurrent use.
+type Authenticator interface {
+	Authenticate(ctx context.Context, req *http.Request) error
+}
+
+// CredentialInvalidator is implemented by authenticators that obtain their
+// credentials from somewhere else. When the server answers 401 the client
+// invalidates the credentials and sends the request once more with fresh
+// ones.
+type CredentialInvalidator interface {
+	Invalidate()
+}
+
+// StaticKey authenticates with a fixed access key.
+type StaticKey string
+
+func (k StaticKey) Authenticate(_ context.Context, req *http.Request) error {
+	req.Header.Set(headerAccessKey, string(k))
+	return nil
+}
+
+func (k StaticKey) String() string {
+	return "access key " + MaskSecret(string(k))
+}
+
+// keyLoader caches an access key loaded on first use until it is
+// invalidated.
+type keyLoader struct {
+	load func(ctx context.Context) (string, error)
+
+	mu  sync.Mutex
+	key string
+}
+
+func (l *keyLoader) Authenticate(ctx context.Context, req *http.Request) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.key == "" {
+		key, err := l.load(ctx)
+		if err != nil {
+			return err
+		}
+		if key == "" {
+			return errors.New("access key is empty")
+		}
+		l.key = key
+	}
+	req.Header.Set(headerAccessKey, l.key)
+	return nil
+}
+
+func (l *keyLoader) Invalidate() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.key = ""
+}
+
+// KeyFileAuthenticator authenticates with an access key read from a file,
+// so the key never appears in the command line or the config file.
+type KeyFileAuthenticator struct {
+	keyLoader
+	path string
+}
+
+// NewKeyFileAuthenticator reads the access key from path on first use, and
+// again after the server rejects it. Surrounding whitespace is ignored.
+func NewKeyFileAuthenticator(path string) *KeyFileAuthenticator {
+	a := &KeyFileAuthenticator{path: path}
+	a.load = func(context.Context) (string, error) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("read access key file: %w", err)
+		}
+		return strings.TrimSpace(string(data)), nil
+	}
+	return a
+}
+
+func (a *KeyFileAuthenticator) String() string {
+	return "access key from file " + a.path
+}
+
+// KeyCommandAuthenticator authenticates with an access key printed by a
+// command, such as a password manager's.
+type KeyCommandAuthenticator struct {
+	keyLoader
+	command string
+}
+
+// NewKeyCommandAuthenticator runs command through the shell on first use,
+// and again after the server rejects the key, taking the first line of its
+// output as the access key. This matches password managers like pass,
+// which print the password on the first line.
+func NewKeyCommandAuthenticator(command string) *KeyCommandAuthenticator {
+	a := &KeyCommandAuthenticator{command: command}
+	a.load = func(ctx context.Context) (string, error) {
+		var cmd *exec.Cmd
+		if runtime.GOOS == "windows" {
+			cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+		} else {
+			cmd = exec.CommandContext(ctx, "sh", "-c", command)
+		}
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+
+		out, err := cmd.Output()
+		if err != nil {
+			if msg := strings.TrimSpace(stderr.String()); msg != "" {
+				return "", fmt.Errorf("run access key command %q: %w: %s", command, err, msg)
+			}
+			return "", fmt.Errorf("run access key command %q: %w", command, err)
+		}
+		line, _, _ := strings.Cut(string(out), "\n")
+		return strings.TrimSpace(line), nil
+	}
+	return a
+}
+
+func (a *KeyCommandAuthenticator) String() string {
+	return fmt.Sprintf("access key from command %q", a.command)
+}
+
+// OAuth2Options configure the OAuth2 client credentials grant (RFC 6749
+// section 4.4).
+type OAuth2Options struct {
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+}
+
+// OAuth2Authenticator authenticates with a bearer token obtained with the
+// OAuth2 client credentials grant. The token is reused until shortly before
+// it expires, or until the server rejects it.
+type OAuth2Authenticator struct {
+	opts OAuth2Options
+
+	mu        sync.Mutex
+	token     string
+	expiry    time.Time
+	transport http.RoundTripper
+}
+
+// NewOAuth2Authenticator returns an authenticator requesting tokens from
+// opts.TokenURL. Token requests use the TLS and proxy settings of the
+// client the authenticator is set on.
+func NewOAuth2Authenticator(opts OAuth2Options) (*OAuth2Authenticator, error) {
+	parsed, err := url.Parse(opts.TokenURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse token URL: %w", err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return nil, fmt.Errorf("token URL %q must be an http or https URL", opts.TokenURL)
+	}
+	if opts.ClientID == "" {
+		return nil, errors.New("client ID is required")
+	}
+	return &OAuth2Authenticator{opts: opts}, nil
+}
+
+func (a *OAuth2Authenticator) String() string {
+	return fmt.Sprintf("OAuth2 client %q at %s", a.opts.ClientID, a.opts.TokenURL)
+}
+
+func (a *OAuth2Authenticator) Authenticate(ctx context.Context, req *http.Request) error {
+	token, err := a.validToken(ctx)
+	if err != nil {
+		return err
+	}
+	req.Header.Set(headerAuthorization, "Bearer "+token)
+	return nil
+}
+
+func (a *OAuth2Authenticator) Invalidate() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.token = ""
+}
+
+// setTransport sets the transport token requests are sent with.
+func (a *OAuth2Authenticator) setTransport(transport http.RoundTripper) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.transport = transport
+}
+
+// validToken returns the current token, requesting a new one if there is
+// none or it is about to expire. Concurrent requests wait for a single
+// token request.
+func (a *OAuth2Authenticator) validToken(ctx context.Context) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.token != "" && (a.expiry.IsZero() || time.Now().Before(a.expiry.Add(-tokenExpiryLeeway))) {
+		return a.token, nil
+	}
+
+	token, expiresIn, err := a.requestToken(ctx)
+	if err != nil {
+		return "", err
+	}
+	a.token = token
+	a.expiry = time.Time{}
+	if expiresIn > 0 {
+		a.expiry = time.Now().Add(time.Duration(expiresIn) * time.Second)
+	}
+	return a.token, nil
+}
+
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	TokenType        string `json:"token_type"`
+	ExpiresIn        int64  `json:"expires_in"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+func (a *OAuth2Authenticator) requestToken(ctx context.Context) (string, int64, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(a.opts.Scopes) > 0 {
+		form.Set("scope", strings.Join(a.opts.Scopes, " "))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.opts.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set(headerContentType, "application/x-www-form-urlencoded")
+	req.Header.Set(headerAccept, contentTypeJSON)
+	// RFC 6749 section 2.3.1: the credentials are form encoded first.
+	req.SetBasicAuth(url.QueryEscape(a.opts.ClientID), url.QueryEscape(a.opts.ClientSecret))
+
+	resp, err := (&http.Client{Transport: a.transport}).Do(req)
+	if err != nil {
+		return "", 0, fmt.Errorf("request OAuth2 token: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", 0, fmt.Errorf("read OAuth2 token response: %w", err)
+	}
+
+	var token tokenResponse
+	if err := json.Unmarshal(body, &token); err != nil && resp.StatusCode == http.StatusOK {
+		return "", 0, fmt.Errorf("decode OAuth2 token response: %w", err)
+	}
+	switch {
+	case resp.StatusCode != http.StatusOK && token.Error != "":
+		if token.ErrorDescription != "" {
+			return "", 0, fmt.Errorf("request OAuth2 token: %s: %s", token.Error, token.ErrorDescription)
+		}
+		return "", 0, fmt.Errorf("request OAuth2 token: %s", token.Error)
+	case resp.StatusCode != http.StatusOK:
+		return "", 0, fmt.Errorf("request OAuth2 token: status %d", resp.StatusCode)
+	case token.AccessToken == "":
+		return "", 0, errors.New("request OAuth2 token: response has no access_token")
+	case token.TokenType != "" && !strings.EqualFold(token.TokenType, "bearer"):
+		return "", 0, fmt.Errorf("request OAuth2 token: unsupported token type %q", token.TokenType)
+	}
+	return token.AccessToken, token.ExpiresIn, nil
+}
+
+// errAuthenticate marks failures to obtain credentials, which happen before
+// any request is sent.
+var errAuthenticate = errors.New("authenticate")
+
+// authenticate adds the credentials of auth to req.
+func authenticate(ctx context.Context, auth Authenticator, req *http.Request) error {
+	if auth == nil {
+		return nil
+	}
+	if err := auth.Authenticate(ctx, req); err != nil {
+		return fmt.Errorf("%w: %w", errAuthenticate, err)
+	}
+	return nil
+}
+
+// MaskSecret shows only the end of a secret, such as an access key, for
+// printing.
+func MaskSecret(secret string) string {
+	if len(secret) <= 4 {
+		return "****"
+	}
+	return "****" + secret[len(secret)-4:]
+}
@@ -0,0 +1,370 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/EnSync-engine/CLI/pkg/signing"
+)
+
+const (
+	headerCacheControl    = "Cache-Control"
+	headerETag            = "ETag"
+	headerLastModified    = "Last-Modified"
+	headerIfNoneMatch     = "If-None-Match"
+	headerIfModifiedSince = "If-Modified-Since"
+)
+
+// ResponseCache stores successful GET responses on disk, one directory per
+// access key. Entries are served without contacting the server for up to
+// their max-age, capped at ttl, and revalidated with
+// If-None-Match/If-Modified-Since afterwards. Access key responses, which
+// carry the keys themselves, and responses carrying key pairs are never
+// stored.
+type ResponseCache struct {
+	dir     string
+	ttl     time.Duration
+	assumed bool
+}
+
+// CacheOption configures a ResponseCache.
+type CacheOption func(*ResponseCache)
+
+// WithAssumedFreshness serves responses without a max-age for the whole
+// ttl. Without it they are revalidated on every use, and responses that
+// cannot be revalidated either are not stored at all.
+func WithAssumedFreshness() CacheOption {
+	return func(c *ResponseCache) {
+		c.assumed = true
+	}
+}
+
+// NewResponseCache returns a cache rooted at dir. A ttl of zero makes every
+// request go to the server, revalidating stored entries when possible.
+func NewResponseCache(dir string, ttl time.Duration, opts ...CacheOption) *ResponseCache {
+	c := &ResponseCache{dir: dir, ttl: ttl}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+type noCacheContextKey struct{}
+
+// WithoutCache returns a context whose requests always go to the server
+// and whose responses are not stored, for checks that must not pass on
+// the strength of an earlier response.
+func WithoutCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noCacheContextKey{}, true)
+}
+
+// Clear removes every cached response.
+func (c *ResponseCache) Clear() error {
+	return os.RemoveAll(c.dir)
+}
+
+// cacheEntry is the on-disk form of a cached response. The URL is not kept,
+// only its hash in the file name.
+type cacheEntry struct {
+	Resource string      `json:"resource"`
+	Header   http.Header `json:"header"`
+	Body     []byte      `json:"body"`
+	StoredAt time.Time   `json:"storedAt"`
+}
+
+func (e *cacheEntry) revalidatable() bool {
+	return e.Header.Get(headerETag) != "" || e.Header.Get(headerLastModified) != ""
+}
+
+type cacheTransport struct {
+	next     http.RoundTripper
+	cache    *ResponseCache
+	basePath string
+	logger   *zap.Logger
+}
+
+// NewCacheMiddleware serves GET requests from cache and invalidates the
+// cached entries of a resource when a write to it succeeds. baseURL is used
+// to tell which resource ("event", "access-key", ...) a request addresses.
+func NewCacheMiddleware(cache *ResponseCache, baseURL string, logger *zap.Logger) Middleware {
+	basePath := basePathOf(baseURL)
+
+	return func(next http.RoundTripper) http.RoundTripper {
+		return &cacheTransport{
+			next:     next,
+			cache:    cache,
+			basePath: basePath,
+			logger:   logger,
+		}
+	}
+}
+
+func (t *cacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	switch req.Method {
+	case http.MethodGet:
+		if bypass, _ := req.Context().Value(noCacheContextKey{}).(bool); bypass {
+			return t.next.RoundTrip(req)
+		}
+		// Access key responses and URLs hold raw access keys.
+		if t.resource(req.URL) == "access-key" {
+			return t.next.RoundTrip(req)
+		}
+	case http.MethodHead, http.MethodOptions:
+		return t.next.RoundTrip(req)
+	default:
+		resp, err := t.next.RoundTrip(req)
+		if err == nil && resp.StatusCode < http.StatusBadRequest {
+			t.invalidate(req)
+		}
+		return resp, err
+	}
+
+	path := t.entryPath(req)
+	entry := t.load(path)
+
+	if entry != nil && t.fresh(entry) {
+		t.logger.Debug("API response served from cache", zap.String("url", req.URL.String()))
+		return entry.response(req), nil
+	}
+
+	// Conditional headers set by the caller are left alone; the response
+	// then belongs to the caller rather than to the cache.
+	conditional := req.Header.Get(headerIfNoneMatch) != "" || req.Header.Get(headerIfModifiedSince) != ""
+	if entry != nil && entry.revalidatable() && !conditional {
+		req = req.Clone(req.Context())
+		if etag := entry.Header.Get(headerETag); etag != "" {
+			req.Header.Set(headerIfNoneMatch, etag)
+		}
+		if modified := entry.Header.Get(headerLastModified); modified != "" {
+			req.Header.Set(headerIfModifiedSince, modified)
+		}
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified && entry != nil && !conditional {
+		_ = resp.Body.Close()
+		for _, name := range []string{headerCacheControl, headerETag, headerLastModified} {
+			if value := resp.Header.Get(name); value != "" {
+				entry.Header.Set(name, value)
+			}
+		}
+		entry.StoredAt = time.Now()
+		t.store(path, entry)
+		t.logger.Debug("API response revalidated", zap.String("url", req.URL.String()))
+		return entry.response(req), nil
+	}
+
+	if resp.StatusCode != http.StatusOK || conditional {
+		return resp, nil
+	}
+
+	return t.save(path, req, resp)
+}
+
+// save stores resp when its Cache-Control allows it and it holds no key
+// pair, and returns an equivalent response whose body can still be read by
+// the caller.
+func (t *cacheTransport) save(path string, req *http.Request, resp *http.Response) (*http.Response, error) {
+	if hasDirective(resp.Header, "no-store") {
+		_ = os.Remove(path)
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	entry := &cacheEntry{
+		Resource: t.resource(req.URL),
+		Header:   resp.Header.Clone(),
+		Body:     body,
+		StoredAt: time.Now(),
+	}
+	switch {
+	case containsKeyPair(body):
+		_ = os.Remove(path)
+	case t.lifetime(entry.Header) > 0 || entry.revalidatable():
+		t.store(path, entry)
+	}
+
+	return resp, nil
+}
+
+// fresh reports whether entry may be served without contacting the server.
+// It is evaluated against the current ttl, so lowering --cache-ttl takes
+// effect on entries stored earlier.
+func (t *cacheTransport) fresh(entry *cacheEntry) bool {
+	return time.Since(entry.StoredAt) < t.lifetime(entry.Header)
+}
+
+// lifetime is how long a response may be served without revalidation: its
+// max-age capped at the ttl, or without one, nothing unless freshness is
+// assumed.
+func (t *cacheTransport) lifetime(header http.Header) time.Duration {
+	if hasDirective(header, "no-cache") {
+		return 0
+	}
+	maxAge, ok := directiveValue(header, "max-age")
+	if !ok {
+		if t.cache.assumed {
+			return t.cache.ttl
+		}
+		return 0
+	}
+	seconds, err := strconv.Atoi(maxAge)
+	if err != nil {
+		return 0
+	}
+	return min(time.Duration(seconds)*time.Second, t.cache.ttl)
+}
+
+// containsKeyPair reports whether body holds a service key pair, whose
+// private key must not be written to the cache.
+func containsKeyPair(body []byte) bool {
+	return bytes.Contains(body, []byte(`"service_key_pair"`)) || bytes.Contains(body, []byte(`"private_key"`))
+}
+
+// invalidate removes the cached entries of the resource req wrote to.
+// Workspaces contain events and access keys, so writing one clears
+// everything cached for the access key.
+func (t *cacheTransport) invalidate(req *http.Request) {
+	dir := t.keyDir(req)
+	resource := t.resource(req.URL)
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return
+	}
+	for _, file := range files {
+		entry := t.load(file)
+		if entry == nil || resource == "workspace" || entry.Resource == resource {
+			_ = os.Remove(file)
+		}
+	}
+	t.logger.Debug("API cache invalidated", zap.String("resource", resource))
+}
+
+func (t *cacheTransport) resource(u *url.URL) string {
+	return resourceOf(t.basePath, u)
+}
+
+// basePathOf returns the path of baseURL without a trailing slash.
+func basePathOf(baseURL string) string {
+	if parsed, err := url.Parse(baseURL); err == nil {
+		return strings.TrimSuffix(parsed.Path, "/")
+	}
+	return ""
+}
+
+// resourceOf returns the first path segment of u after basePath, naming the
+// resource ("event", "access-key", ...) a request addresses.
+func resourceOf(basePath string, u *url.URL) string {
+	path := strings.TrimPrefix(strings.TrimPrefix(u.Path, basePath), "/")
+	resource, _, _ := strings.Cut(path, "/")
+	if resource == "access" {
+		// Service key pairs belong to access keys.
+		return "access-key"
+	}
+	return resource
+}
+
+// keyDir returns the directory of the request's credentials: its access
+// key, its bearer token for authenticators that send one, or the public key
+// it is signed with when it carries neither.
+func (t *cacheTransport) keyDir(req *http.Request) string {
+	credentials := req.Header.Get(headerAccessKey)
+	if credentials == "" {
+		credentials = req.Header.Get(headerAuthorization)
+	}
+	if credentials == "" {
+		credentials = req.Header.Get(signing.HeaderKey)
+	}
+	return filepath.Join(t.cache.dir, hashKey(credentials))
+}
+
+// entryPath keys an entry by credentials, workspace scope and URL, so
+// responses are never shared between credentials or scopes.
+func (t *cacheTransport) entryPath(req *http.Request) string {
+	key := hashKey(req.Header.Get(headerWorkspace) + "\x00" + req.URL.String())
+	return filepath.Join(t.keyDir(req), key+".json")
+}
+
+func (t *cacheTransport) load(path string) *cacheEntry {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil
+	}
+	return &entry
+}
+
+// store writes entry to path. Failures only cost a later cache miss.
+func (t *cacheTransport) store(path string, entry *cacheEntry) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.logger.Debug("API cache write failed", zap.Error(err))
+	}
+}
+
+func (e *cacheEntry) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
+
+func hashKey(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
+func hasDirective(header http.Header, name string) bool {
+	_, ok := directiveValue(header, name)
+	return ok
+}
+
+func directiveValue(header http.Header, name string) (string, bool) {
+	for _, directive := range strings.Split(header.Get(headerCacheControl), ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		if strings.EqualFold(key, name) {
+			return strings.Trim(value, `"`), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,294 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type CircuitState string
+
+const (
+	CircuitClosed   CircuitState = "closed"
+	CircuitOpen     CircuitState = "open"
+	CircuitHalfOpen CircuitState = "half-open"
+)
+
+// CircuitBreakerPolicy controls when the circuit of an endpoint opens.
+// It opens after ConsecutiveFailures failed requests in a row, or once at
+// least FailureRate of the last Window requests failed; a zero threshold
+// disables that check. After OpenTimeout a single probe request is let
+// through: its success closes the circuit, its failure opens it again.
+type CircuitBreakerPolicy struct {
+	ConsecutiveFailures int
+	FailureRate         float64
+	Window              int
+	OpenTimeout         time.Duration
+}
+
+func DefaultCircuitBreakerPolicy() CircuitBreakerPolicy {
+	return CircuitBreakerPolicy{
+		ConsecutiveFailures: 5,
+		FailureRate:         0.5,
+		Window:              20,
+		OpenTimeout:         30 * time.Second,
+	}
+}
+
+// CircuitOpenError is returned without contacting the server while the
+// circuit of an endpoint is open.
+type CircuitOpenError struct {
+	Endpoint string
+	RetryAt  time.Time
+}
+
+func (e *CircuitOpenError) Error() string {
+	wait := max(time.Until(e.RetryAt), 0).Round(time.Second)
+	return fmt.Sprintf("circuit breaker open for %s requests after repeated server failures: retry in %s", e.Endpoint, wait)
+}
+
+// IsCircuitOpen reports whether err wraps a CircuitOpenError.
+func IsCircuitOpen(err error) bool {
+	var openErr *CircuitOpenError
+	return errors.As(err, &openErr)
+}
+
+// CircuitStatus describes the circuit of one endpoint.
+type CircuitStatus struct {
+	Endpoint            string       `json:"endpoint"`
+	State               CircuitState `json:"state"`
+	ConsecutiveFailures int          `json:"consecutiveFailures"`
+	FailureRate         float64      `json:"failureRate"`
+	RetryAt             *time.Time   `json:"retryAt,omitempty"`
+}
+
+// CircuitBreaker tracks request failures per endpoint and stops sending
+// requests to endpoints that keep failing, so a struggling server is not
+// hammered with retries. Endpoints are the resources of the API ("event",
+// "access-key", ...).
+type CircuitBreaker struct {
+	policy CircuitBreakerPolicy
+
+	mu       sync.Mutex
+	circuits map[string]*circuit
+}
+
+type circuit struct {
+	state       CircuitState
+	consecutive int
+	// outcomes holds whether each of the last policy.Window requests
+	// failed, oldest first.
+	outcomes []bool
+	retryAt  time.Time
+	probing  bool
+}
+
+func NewCircuitBreaker(policy CircuitBreakerPolicy) *CircuitBreaker {
+	return &CircuitBreaker{
+		policy:   policy,
+		circuits: make(map[string]*circuit),
+	}
+}
+
+// States returns the circuits of the endpoints requested so far, sorted
+// by endpoint.
+func (b *CircuitBreaker) States() []CircuitStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	states := make([]CircuitStatus, 0, len(b.circuits))
+	for endpoint, c := range b.circuits {
+		status := CircuitStatus{
+			Endpoint:            endpoint,
+			State:               c.state,
+			ConsecutiveFailures: c.consecutive,
+			FailureRate:         c.failureRate(),
+		}
+		if c.state == CircuitOpen {
+			retryAt := c.retryAt
+			status.RetryAt = &retryAt
+		}
+		states = append(states, status)
+	}
+	slices.SortFunc(states, func(a, b CircuitStatus) int { return strings.Compare(a.Endpoint, b.Endpoint) })
+	return states
+}
+
+// allow reports whether a request to endpoint may be sent, and the state
+// the circuit moved from if the call changed it.
+func (b *CircuitBreaker) allow(endpoint string) (from CircuitState, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c := b.circuit(endpoint)
+	switch c.state {
+	case CircuitOpen:
+		if time.Now().Before(c.retryAt) {
+			return "", &CircuitOpenError{Endpoint: endpoint, RetryAt: c.retryAt}
+		}
+		c.state = CircuitHalfOpen
+		c.probing = true
+		return CircuitOpen, nil
+	case CircuitHalfOpen:
+		if c.probing {
+			return "", &CircuitOpenError{Endpoint: endpoint, RetryAt: time.Now()}
+		}
+		c.probing = true
+	}
+	return "", nil
+}
+
+// record counts the outcome of a request to endpoint and returns the state
+// the circuit moved from if the outcome changed it.
+func (b *CircuitBreaker) record(endpoint string, failed bool) (from CircuitState) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c := b.circuit(endpoint)
+	switch c.state {
+	case CircuitOpen:
+		// A request sent before the circuit opened.
+		return ""
+	case CircuitHalfOpen:
+		c.probing = false
+		if failed {
+			c.open(b.policy.OpenTimeout)
+		} else {
+			*c = circuit{state: CircuitClosed}
+		}
+		return CircuitHalfOpen
+	}
+
+	if failed {
+		c.consecutive++
+	} else {
+		c.consecutive = 0
+	}
+	c.outcomes = append(c.outcomes, failed)
+	if len(c.outcomes) > b.policy.Window {
+		c.outcomes = c.outcomes[len(c.outcomes)-b.policy.Window:]
+	}
+
+	if b.trips(c) {
+		c.open(b.policy.OpenTimeout)
+		return CircuitClosed
+	}
+	return ""
+}
+
+// release lets another probe through after a probe was cancelled.
+func (b *CircuitBreaker) release(endpoint string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.circuit(endpoint).probing = false
+}
+
+func (b *CircuitBreaker) trips(c *circuit) bool {
+	p := b.policy
+	if p.ConsecutiveFailures > 0 && c.consecutive >= p.ConsecutiveFailures {
+		return true
+	}
+	return p.FailureRate > 0 && p.Window > 0 && len(c.outcomes) >= p.Window && c.failureRate() >= p.FailureRate
+}
+
+func (b *CircuitBreaker) circuit(endpoint string) *circuit {
+	c, ok := b.circuits[endpoint]
+	if !ok {
+		c = &circuit{state: CircuitClosed}
+		b.circuits[endpoint] = c
+	}
+	return c
+}
+
+func (c *circuit) open(timeout time.Duration) {
+	c.state = CircuitOpen
+	c.retryAt = time.Now().Add(timeout)
+	c.outcomes = nil
+}
+
+func (c *circuit) failureRate() float64 {
+	if len(c.outcomes) == 0 {
+		return 0
+	}
+	failures := 0
+	for _, failed := range c.outcomes {
+		if failed {
+			failures++
+		}
+	}
+	return float64(failures) / float64(len(c.outcomes))
+}
+
+type circuitBreakerTransport struct {
+	next     http.RoundTripper
+	breaker  *CircuitBreaker
+	basePath string
+	logger   *zap.Logger
+}
+
+// NewCircuitBreakerMiddleware fails requests fast with a CircuitOpenError
+// while the circuit of their endpoint is open. Connection errors and 5xx
+// responses count as failures. baseURL is used to tell which endpoint a
+// request addresses.
+func NewCircuitBreakerMiddleware(breaker *CircuitBreaker, baseURL string, logger *zap.Logger) Middleware {
+	basePath := basePathOf(baseURL)
+
+	return func(next http.RoundTripper) http.RoundTripper {
+		return &circuitBreakerTransport{
+			next:     next,
+			breaker:  breaker,
+			basePath: basePath,
+			logger:   logger,
+		}
+	}
+}
+
+func (t *circuitBreakerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	endpoint := resourceOf(t.basePath, req.URL)
+
+	from, err := t.breaker.allow(endpoint)
+	if err != nil {
+		t.logger.Debug("API request rejected by circuit breaker",
+			zap.String("method", req.Method),
+			zap.String("url", req.URL.String()),
+			zap.String("endpoint", endpoint),
+		)
+		return nil, err
+	}
+	t.logTransition(endpoint, from, CircuitHalfOpen)
+
+	resp, err := t.next.RoundTrip(req)
+
+	// Requests cancelled by the caller say nothing about the server.
+	if err != nil && errors.Is(err, context.Canceled) {
+		t.breaker.release(endpoint)
+		return nil, err
+	}
+	failed := err != nil || resp.StatusCode >= http.StatusInternalServerError
+	if from := t.breaker.record(endpoint, failed); from != "" {
+		to := CircuitClosed
+		if failed {
+			to = CircuitOpen
+		}
+		t.logTransition(endpoint, from, to)
+	}
+	return resp, err
+}
+
+func (t *circuitBreakerTransport) logTransition(endpoint string, from, to CircuitState) {
+	if from == "" {
+		return
+	}
+	t.logger.Debug("Circuit breaker state changed",
+		zap.String("endpoint", endpoint),
+		zap.String("from", string(from)),
+		zap.String("to", string(to)),
+	)
+}
@@ -2,82 +2,245 @@ package api
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
+	"sync"
 	"time"
 
 	"github.com/hashicorp/go-retryablehttp"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
-	"golang.org/x/time/rate"
 
 	"github.com/EnSync-engine/CLI/app/domain"
+	"github.com/EnSync-engine/CLI/pkg/signing"
 )
 
 var _ APIClient = (*Client)(nil)
 
-const (
-	defaultRetryMax     = 3
-	defaultRetryWaitMin = 1 * time.Second
-	defaultRetryWaitMax = 5 * time.Second
-)
-
 type Client struct {
 	baseURL   string
-	accessKey string
+	auth      Authenticator
+	workspace string
+
+	mu             sync.Mutex
+	idempotencyKey string
+	writes         int
+	requestID      string
 
 	http        *http.Client
-	rateLimiter *rate.Limiter
-	log         *zap.Logger
+	transport   http.RoundTripper
+	retryClient *retryablehttp.Client
+	// baseTransport opens connections for the retrying client, and
+	// attemptTransport sends each of its attempts.
+	baseTransport    *http.Transport
+	attemptTransport http.RoundTripper
+	tlsConfig        *tls.Config
+	proxy            ProxyFunc
+	retryPolicy      RetryPolicy
+	middlewares      []Middleware
+	cache            *ResponseCache
+	rateLimiter      *RateLimiter
+	breaker          *CircuitBreaker
+	signingKey       *domain.ServiceKeyPair
+	omitAccessKey    bool
+	tracerProvider   trace.TracerProvider
+	meterProvider    metric.MeterProvider
+	onThrottle       func(wait time.Duration)
+	log              *zap.Logger
 }
 
 func NewClient(baseURL string, options ...ClientOption) *Client {
 	retryable := retryablehttp.NewClient()
-	retryable.RetryMax = defaultRetryMax
-	retryable.RetryWaitMin = defaultRetryWaitMin
-	retryable.RetryWaitMax = defaultRetryWaitMax
 	retryable.Logger = nil
+	baseTransport, _ := retryable.HTTPClient.Transport.(*http.Transport)
 
+	httpClient := retryable.StandardClient()
 	client := &Client{
-		baseURL: baseURL,
-		http:    retryable.StandardClient(),
-		log:     zap.NewNop(),
+		baseURL:       baseURL,
+		http:          httpClient,
+		transport:     httpClient.Transport,
+		retryClient:   retryable,
+		baseTransport: baseTransport,
+		retryPolicy:   DefaultRetryPolicy(),
+		log:           zap.NewNop(),
 	}
 
+	client.Configure(options...)
+
+	return client
+}
+
+// Configure applies options to an existing client and rebuilds its
+// middleware chain. It lets the CLI construct the client up front and
+// finish configuring it once flags and config have been resolved.
+func (c *Client) Configure(options ...ClientOption) {
 	for _, opt := range options {
-		opt(client)
+		opt(c)
 	}
 
-	if client.log != zap.NewNop() {
-		client.http.Transport = NewLoggingMiddleware(client.log)(client.http.Transport)
+	// Attempt middlewares see every attempt, including retries.
+	var attempt []Middleware
+	if c.breaker != nil {
+		attempt = append(attempt, NewCircuitBreakerMiddleware(c.breaker, c.baseURL, c.log))
+	}
+	if c.rateLimiter != nil {
+		c.rateLimiter.setThrottleHandler(c.onThrottle)
+		attempt = append(attempt, NewRateLimitMiddleware(c.rateLimiter))
+	}
+	// Signing comes last so the signed timestamp is taken just before the
+	// attempt is sent.
+	if c.signingKey != nil {
+		attempt = append(attempt, NewSigningMiddleware(*c.signingKey, c.omitAccessKey))
 	}
 
-	return client
+	// The cache sits outside the logger and telemetry so only requests that
+	// reach the server are logged and traced as API requests.
+	var middlewares []Middleware
+	if c.cache != nil {
+		middlewares = append(middlewares, NewCacheMiddleware(c.cache, c.baseURL, c.log))
+	}
+	if c.tracerProvider != nil && c.meterProvider != nil {
+		middlewares = append(middlewares, NewTelemetryMiddleware(c.tracerProvider, c.meterProvider, c.baseURL))
+	}
+	middlewares = append(middlewares, NewLoggingMiddleware(c.log))
+	middlewares = append(middlewares, c.middlewares...)
+
+	if c.retryClient != nil {
+		c.retryPolicy.apply(c.retryClient, c.log)
+		c.attemptTransport = c.connTransport()
+		c.retryClient.HTTPClient.Transport = ChainMiddleware(c.attemptTransport, attempt...)
+	} else {
+		// Without the retrying client there is a single attempt per request.
+		middlewares = append(middlewares, attempt...)
+	}
+	c.http.Transport = ChainMiddleware(c.transport, middlewares...)
+	c.shareTransport()
 }
 
+// SetAccessKey authenticates subsequent requests with a static access key.
+// An empty key sends no credentials.
 func (c *Client) SetAccessKey(key string) {
-	c.accessKey = key
+	if key == "" {
+		c.SetAuthenticator(nil)
+		return
+	}
+	c.SetAuthenticator(StaticKey(key))
 }
 
-func (c *Client) execute(ctx context.Context, method, path string, queryParams url.Values, requestBody any) ([]byte, error) {
-	if err := c.waitForRateLimit(ctx); err != nil {
-		return nil, err
+// SetAuthenticator sets how subsequent requests are authenticated.
+func (c *Client) SetAuthenticator(auth Authenticator) {
+	c.auth = auth
+	c.shareTransport()
+}
+
+// shareTransport lets an authenticator that sends requests of its own use
+// the client's TLS and proxy settings.
+func (c *Client) shareTransport() {
+	if a, ok := c.auth.(interface{ setTransport(http.RoundTripper) }); ok {
+		a.setTransport(c.connectionTransport())
 	}
+}
 
+// connectionTransport returns the transport that sends a single attempt,
+// without middlewares.
+func (c *Client) connectionTransport() http.RoundTripper {
+	if c.retryClient != nil {
+		return c.attemptTransport
+	}
+	return c.transport
+}
+
+// SetIdempotencyKey sets the idempotency key sent with the next write.
+// Later writes use the key with a numeric suffix; see nextIdempotencyKey.
+func (c *Client) SetIdempotencyKey(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.idempotencyKey = key
+	c.writes = 0
+}
+
+// CircuitStates returns the state of the circuit breaker for each endpoint
+// requested so far, or nil without a circuit breaker.
+func (c *Client) CircuitStates() []CircuitStatus {
+	if c.breaker == nil {
+		return nil
+	}
+	return c.breaker.States()
+}
+
+// RateLimitQuota returns the server's rate limit as last reported in its
+// response headers, if any.
+func (c *Client) RateLimitQuota() (RateLimitQuota, bool) {
+	if c.rateLimiter == nil {
+		return RateLimitQuota{}, false
+	}
+	return c.rateLimiter.Quota()
+}
+
+// SetRequestID sets the X-Request-ID sent with subsequent requests. An
+// empty ID gives each request a random one.
+func (c *Client) SetRequestID(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.requestID = id
+}
+
+// SetWorkspace scopes subsequent requests to the workspace path. An empty
+// path removes the scope.
+func (c *Client) SetWorkspace(path string) {
+	c.workspace = path
+}
+
+func (c *Client) execute(ctx context.Context, method, path string, queryParams url.Values, requestBody any) ([]byte, error) {
+	return c.executeWithContentType(ctx, method, path, queryParams, requestBody, contentTypeJSON)
+}
+
+func (c *Client) executeWithContentType(ctx context.Context, method, path string, queryParams url.Values, requestBody any, contentType string) ([]byte, error) {
 	fullURL := buildURL(c.baseURL, path, queryParams)
 	body, err := marshalBody(requestBody)
 	if err != nil {
 		return nil, err
 	}
 
-	request, err := buildRequest(ctx, method, fullURL, body, c.accessKey)
+	ctx, err = c.withIdempotency(ctx, method)
 	if err != nil {
 		return nil, err
 	}
+	ctx, err = c.withRequestID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx = withRetryState(ctx)
 
-	response, err := c.http.Do(request)
+	request, err := buildRequest(ctx, method, fullURL, body, contentType)
 	if err != nil {
-		return nil, fmt.Errorf("http request failed: %w", err)
+		return nil, err
+	}
+	if c.workspace != "" {
+		request.Header.Set(headerWorkspace, c.workspace)
+	}
+	if c.signingKey != nil {
+		// Set ahead of signing so the cache can tell signing keys apart
+		// when no access key is sent.
+		request.Header.Set(signing.HeaderKey, c.signingKey.PublicKey)
+	}
+
+	requestID := request.Header.Get(headerRequestID)
+	response, err := c.do(ctx, request)
+	if err != nil {
+		if errors.Is(err, errAuthenticate) {
+			return nil, err
+		}
+		var openErr *CircuitOpenError
+		if errors.As(err, &openErr) {
+			return nil, openErr
+		}
+		return nil, &RequestError{RequestID: requestID, Err: err}
 	}
 	defer func() { _ = response.Body.Close() }()
 
@@ -87,20 +250,45 @@ func (c *Client) execute(ctx context.Context, method, path string, queryParams u
 	}
 
 	if isErrorStatus(response.StatusCode) {
-		return nil, handleErrorResponse(response.StatusCode, responseBody)
+		// The server's ID wins if it assigned its own.
+		if id := response.Header.Get(headerRequestID); id != "" {
+			requestID = id
+		}
+		return nil, handleErrorResponse(response.StatusCode, responseBody, requestID)
 	}
 
 	return responseBody, nil
 }
 
-func (c *Client) waitForRateLimit(ctx context.Context) error {
-	if c.rateLimiter == nil {
-		return nil
+// do authenticates and sends the request. If the server rejects
+// credentials that can be obtained again, it sends the request once more
+// with fresh ones.
+func (c *Client) do(ctx context.Context, request *http.Request) (*http.Response, error) {
+	auth := c.auth
+	if err := authenticate(ctx, auth, request); err != nil {
+		return nil, err
 	}
-	if err := c.rateLimiter.Wait(ctx); err != nil {
-		return fmt.Errorf("rate limit exceeded: %w", err)
+	response, err := c.http.Do(request)
+	invalidator, ok := auth.(CredentialInvalidator)
+	if err != nil || !ok || response.StatusCode != http.StatusUnauthorized {
+		return response, err
 	}
-	return nil
+
+	_, _ = io.Copy(io.Discard, response.Body)
+	_ = response.Body.Close()
+	invalidator.Invalidate()
+	c.log.Debug("Credentials rejected, retrying with fresh ones", zap.String("url", request.URL.String()))
+
+	retry := request.Clone(ctx)
+	if request.GetBody != nil {
+		if retry.Body, err = request.GetBody(); err != nil {
+			return nil, fmt.Errorf("failed to rewind request body: %w", err)
+		}
+	}
+	if err := authenticate(ctx, auth, retry); err != nil {
+		return nil, err
+	}
+	return c.http.Do(retry)
 }
 
 func (c *Client) ListEvents(ctx context.Context, params *ListParams) (*domain.EventList, error) {
@@ -118,7 +306,7 @@ func (c *Client) ListEvents(ctx context.Context, params *ListParams) (*domain.Ev
 }
 
 func (c *Client) GetEventByName(ctx context.Context, eventName string) (*domain.Event, error) {
-	path := fmt.Sprintf(pathEventByName, url.PathEscape(eventName))
+	path := fmt.Sprintf(pathEventByKey, url.PathEscape(eventName))
 
 	responseData, err := c.execute(ctx, http.MethodGet, path, nil, nil)
 	if err != nil {
@@ -133,6 +321,28 @@ func (c *Client) GetEventByName(ctx context.Context, eventName string) (*domain.
 	return &event, nil
 }
 
+// GetEventByID finds the event with the given ID by walking the event list,
+// since events can only be read by name.
+func (c *Client) GetEventByID(ctx context.Context, id string) (*domain.Event, error) {
+	var found *domain.Event
+	err := WalkPages(ctx, nil, EventPages(c), func(events []*domain.Event) bool {
+		for _, event := range events {
+			if event.ID == id {
+				found = event
+				return false
+			}
+		}
+		return true
+	})
+	switch {
+	case err != nil:
+		return nil, fmt.Errorf("get event (id=%s): %w", id, err)
+	case found == nil:
+		return nil, fmt.Errorf("get event (id=%s): %w", id, ErrNotFound)
+	}
+	return found, nil
+}
+
 func (c *Client) CreateEvent(ctx context.Context, event *domain.Event) error {
 	if _, err := c.execute(ctx, http.MethodPost, pathEvent, nil, event); err != nil {
 		return fmt.Errorf("create event: %w", err)
@@ -141,7 +351,7 @@ func (c *Client) CreateEvent(ctx context.Context, event *domain.Event) error {
 }
 
 func (c *Client) UpdateEvent(ctx context.Context, event *domain.Event) error {
-	path := fmt.Sprintf(pathEventByID, event.ID)
+	path := fmt.Sprintf(pathEventByKey, event.ID)
 	updatePayload := map[string]any{
 		"name":    event.Name,
 		"payload": event.Payload,
@@ -153,6 +363,26 @@ func (c *Client) UpdateEvent(ctx context.Context, event *domain.Event) error {
 	return nil
 }
 
+// PatchEvent applies a JSON Merge Patch (RFC 7396) to the event, so fields
+// left unset in the patch keep their current server-side values.
+func (c *Client) PatchEvent(ctx context.Context, id string, patch *domain.EventPatch) error {
+	path := fmt.Sprintf(pathEventByKey, url.PathEscape(id))
+
+	if _, err := c.executeWithContentType(ctx, http.MethodPatch, path, nil, patch, contentTypeMergePatch); err != nil {
+		return fmt.Errorf("patch event (id=%s): %w", id, err)
+	}
+	return nil
+}
+
+func (c *Client) DeleteEvent(ctx context.Context, id string) error {
+	path := fmt.Sprintf(pathEventByKey, url.PathEscape(id))
+
+	if _, err := c.execute(ctx, http.MethodDelete, path, nil, nil); err != nil {
+		return fmt.Errorf("delete event (id=%s): %w", id, err)
+	}
+	return nil
+}
+
 func (c *Client) ListAccessKeys(ctx context.Context, params *ListParams) (*domain.AccessKeyList, error) {
 	responseData, err := c.execute(ctx, http.MethodGet, pathAccessKey, params.ToQuery(), nil)
 	if err != nil {
@@ -197,6 +427,17 @@ func (c *Client) CreateAccessKey(ctx context.Context, req *domain.CreateAccessKe
 	return &key, nil
 }
 
+// UpdateAccessKey applies a JSON Merge Patch (RFC 7396) to the access key's
+// metadata and state, so fields left unset keep their current values.
+func (c *Client) UpdateAccessKey(ctx context.Context, id string, req *domain.UpdateAccessKeyRequest) error {
+	path := fmt.Sprintf(pathAccessKeyByID, url.PathEscape(id))
+
+	if _, err := c.executeWithContentType(ctx, http.MethodPatch, path, nil, req, contentTypeMergePatch); err != nil {
+		return fmt.Errorf("update access key %q: %w", id, err)
+	}
+	return nil
+}
+
 func (c *Client) DeleteAccessKey(ctx context.Context, id string) error {
 	path := fmt.Sprintf(pathAccessKeyByID, url.PathEscape(id))
 
@@ -265,11 +506,61 @@ func (c *Client) ListWorkspaces(ctx context.Context, params *ListParams) (*domai
 	return &workspaces, nil
 }
 
-func (c *Client) CreateWorkspace(ctx context.Context, name string) error {
-	req := &domain.CreateWorkspaceRequest{Name: name}
+func (c *Client) GetWorkspace(ctx context.Context, id string) (*domain.Workspace, error) {
+	path := fmt.Sprintf(pathWorkspaceByID, url.PathEscape(id))
 
+	responseData, err := c.execute(ctx, http.MethodGet, path, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("get workspace %q: %w", id, err)
+	}
+
+	var workspace domain.Workspace
+	if err := unmarshalResponse(responseData, &workspace); err != nil {
+		return nil, err
+	}
+
+	return &workspace, nil
+}
+
+func (c *Client) CreateWorkspace(ctx context.Context, req *domain.CreateWorkspaceRequest) error {
 	if _, err := c.execute(ctx, http.MethodPost, pathWorkspace, nil, req); err != nil {
 		return fmt.Errorf("create workspace: %w", err)
 	}
 	return nil
 }
+
+func (c *Client) UpdateWorkspace(ctx context.Context, id string, req *domain.UpdateWorkspaceRequest) error {
+	path := fmt.Sprintf(pathWorkspaceByID, url.PathEscape(id))
+
+	if _, err := c.executeWithContentType(ctx, http.MethodPatch, path, nil, req, contentTypeMergePatch); err != nil {
+		return fmt.Errorf("update workspace %q: %w", id, err)
+	}
+	return nil
+}
+
+// MoveWorkspace re-parents the workspace. An empty parentID moves it to the root.
+func (c *Client) MoveWorkspace(ctx context.Context, id, parentID string) error {
+	path := fmt.Sprintf(pathWorkspaceMove, url.PathEscape(id))
+	req := &domain.MoveWorkspaceRequest{ParentID: parentID}
+
+	if _, err := c.execute(ctx, http.MethodPost, path, nil, req); err != nil {
+		return fmt.Errorf("move workspace %q: %w", id, err)
+	}
+	return nil
+}
+
+// DeleteWorkspace removes the workspace. Without recursive the server
+// rejects workspaces that still have children.
+func (c *Client) DeleteWorkspace(ctx context.Context, id string, recursive bool) error {
+	path := fmt.Sprintf(pathWorkspaceByID, url.PathEscape(id))
+
+	var query url.Values
+	if recursive {
+		query = url.Values{"recursive": []string{"true"}}
+	}
+
+	if _, err := c.execute(ctx, http.MethodDelete, path, query, nil); err != nil {
+		return fmt.Errorf("delete workspace %q: %w", id, err)
+	}
+	return nil
+}
@@ -1,15 +1,25 @@
 package api
 
 import (
+	"crypto/tls"
 	"net/http"
 	"time"
 
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
-	"golang.org/x/time/rate"
+
+	"github.com/EnSync-engine/CLI/app/domain"
 )
 
 type ClientOption func(*Client)
 
+func WithBaseURL(baseURL string) ClientOption {
+	return func(c *Client) {
+		c.baseURL = baseURL
+	}
+}
+
 func WithLogger(logger *zap.Logger) ClientOption {
 	return func(c *Client) {
 		c.log = logger
@@ -22,14 +32,93 @@ func WithTimeout(timeout time.Duration) ClientOption {
 	}
 }
 
+// WithRateLimit paces requests to rps per second with bursts of up to
+// burst, adapting to the server's rate limit headers; see RateLimiter.
 func WithRateLimit(rps float64, burst int) ClientOption {
 	return func(c *Client) {
-		c.rateLimiter = rate.NewLimiter(rate.Limit(rps), burst)
+		c.rateLimiter = NewRateLimiter(rps, burst)
 	}
 }
 
+// WithThrottleHandler calls handler whenever the server's rate limit
+// pauses requests, with how long they are paused for.
+func WithThrottleHandler(handler func(wait time.Duration)) ClientOption {
+	return func(c *Client) {
+		c.onThrottle = handler
+	}
+}
+
+// WithHTTPClient replaces the default retrying HTTP client. Retries, TLS
+// and proxies are then up to httpClient, and WithRetryPolicy, WithTLSConfig
+// and WithProxy have no effect.
 func WithHTTPClient(httpClient *http.Client) ClientOption {
 	return func(c *Client) {
 		c.http = httpClient
+		c.transport = httpClient.Transport
+		c.retryClient = nil
+	}
+}
+
+// WithRetryPolicy sets how failed requests are retried.
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(c *Client) {
+		c.retryPolicy = policy
+	}
+}
+
+// WithCache serves GET responses from cache, see NewCacheMiddleware.
+func WithCache(cache *ResponseCache) ClientOption {
+	return func(c *Client) {
+		c.cache = cache
+	}
+}
+
+// WithCircuitBreaker fails requests fast while their endpoint keeps
+// failing; see NewCircuitBreakerMiddleware.
+func WithCircuitBreaker(breaker *CircuitBreaker) ClientOption {
+	return func(c *Client) {
+		c.breaker = breaker
+	}
+}
+
+// WithTelemetry traces requests and records request metrics with the given
+// providers; see NewTelemetryMiddleware.
+func WithTelemetry(tracerProvider trace.TracerProvider, meterProvider metric.MeterProvider) ClientOption {
+	return func(c *Client) {
+		c.tracerProvider = tracerProvider
+		c.meterProvider = meterProvider
+	}
+}
+
+// WithTLSConfig sets how connections to the server are secured; see
+// NewTLSConfig.
+func WithTLSConfig(cfg *tls.Config) ClientOption {
+	return func(c *Client) {
+		c.tlsConfig = cfg
+	}
+}
+
+// WithProxy sets the proxy requests go through; see NewProxyFunc. Without
+// it, the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables apply.
+func WithProxy(proxy ProxyFunc) ClientOption {
+	return func(c *Client) {
+		c.proxy = proxy
+	}
+}
+
+// WithSigning signs every request with the service key pair; see
+// NewSigningMiddleware. A nil key pair turns signing off.
+func WithSigning(keyPair *domain.ServiceKeyPair) ClientOption {
+	return func(c *Client) {
+		c.signingKey = keyPair
+	}
+}
+
+// WithOmitAccessKey stops signed requests from carrying the access key in
+// the X-ACCESS-KEY header, leaving the signing key pair to identify the
+// caller. It has no effect without WithSigning.
+func WithOmitAccessKey(omit bool) ClientOption {
+	return func(c *Client) {
+		c.omitAccessKey = omit
 	}
 }
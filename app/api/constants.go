@@ -2,18 +2,26 @@ package api
 
 const (
 	// HTTP headers
-	headerAccessKey   = "X-ACCESS-KEY"
-	headerContentType = "Content-Type"
-	headerAccept      = "Accept"
-	contentTypeJSON   = "application/json"
+	headerAccessKey       = "X-ACCESS-KEY"
+	headerAuthorization   = "Authorization"
+	headerContentType     = "Content-Type"
+	headerAccept          = "Accept"
+	headerWorkspace       = "X-ENSYNC-WORKSPACE"
+	headerIdempotencyKey  = "Idempotency-Key"
+	headerRequestID       = "X-Request-ID"
+	headerServerVersion   = "X-EnSync-Version"
+	contentTypeJSON       = "application/json"
+	contentTypeMergePatch = "application/merge-patch+json"
 
-	// API paths
+	// API paths. The server addresses an event at /event/{key}: reads take
+	// its name and writes its ID. There is no route reading an event by ID.
 	pathEvent                = "/event"
 	pathAccessKey            = "/access-key"
 	pathWorkspace            = "/workspace"
+	pathWorkspaceByID        = "/workspace/%s"
+	pathWorkspaceMove        = "/workspace/%s/move"
 	pathServiceKeyPair       = "/access/service-key-pair"
 	pathAccessKeyPermissions = "/access-key/%s/permissions"
 	pathAccessKeyByID        = "/access-key/%s"
-	pathEventByName          = "/event/%s"
-	pathEventByID            = "/event/%s"
+	pathEventByKey           = "/event/%s"
 )
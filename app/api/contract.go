@@ -15,14 +15,18 @@ type APIClient interface {
 type EventService interface {
 	ListEvents(ctx context.Context, params *ListParams) (*domain.EventList, error)
 	GetEventByName(ctx context.Context, name string) (*domain.Event, error)
+	GetEventByID(ctx context.Context, id string) (*domain.Event, error)
 	CreateEvent(ctx context.Context, event *domain.Event) error
 	UpdateEvent(ctx context.Context, event *domain.Event) error
+	PatchEvent(ctx context.Context, id string, patch *domain.EventPatch) error
+	DeleteEvent(ctx context.Context, id string) error
 }
 
 type AccessKeyService interface {
 	ListAccessKeys(ctx context.Context, params *ListParams) (*domain.AccessKeyList, error)
 	GetAccessKeyByID(ctx context.Context, id string) (*domain.AccessKeyPermissions, error)
 	CreateAccessKey(ctx context.Context, req *domain.CreateAccessKeyRequest) (*domain.AccessKey, error)
+	UpdateAccessKey(ctx context.Context, id string, req *domain.UpdateAccessKeyRequest) error
 	DeleteAccessKey(ctx context.Context, id string) error
 	GetAccessKeyPermissions(ctx context.Context, key string) (*domain.AccessKeyPermissions, error)
 	SetAccessKeyPermissions(ctx context.Context, key string, permissions *domain.Permissions) error
@@ -31,5 +35,9 @@ type AccessKeyService interface {
 
 type WorkspaceService interface {
 	ListWorkspaces(ctx context.Context, params *ListParams) (*domain.WorkspaceList, error)
-	CreateWorkspace(ctx context.Context, name string) error
+	GetWorkspace(ctx context.Context, id string) (*domain.Workspace, error)
+	CreateWorkspace(ctx context.Context, req *domain.CreateWorkspaceRequest) error
+	UpdateWorkspace(ctx context.Context, id string, req *domain.UpdateWorkspaceRequest) error
+	MoveWorkspace(ctx context.Context, id, parentID string) error
+	DeleteWorkspace(ctx context.Context, id string, recursive bool) error
 }
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net/http"
+
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+type (
+	idempotencyKeyContextKey struct{}
+	requestMethodContextKey  struct{}
+)
+
+// WithIdempotencyKey returns a context whose mutating requests carry key in
+// the Idempotency-Key header, so the server can recognise a replayed write
+// and return the original result instead of applying it twice.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyContextKey{}, key)
+}
+
+func idempotencyKeyFromContext(ctx context.Context) string {
+	key, _ := ctx.Value(idempotencyKeyContextKey{}).(string)
+	return key
+}
+
+// withIdempotency prepares ctx for a request: mutating requests get an
+// idempotency key unless one was supplied, and the method is recorded for
+// IdempotentRetryPolicy. Retries reuse the request and so keep the key.
+func (c *Client) withIdempotency(ctx context.Context, method string) (context.Context, error) {
+	ctx = context.WithValue(ctx, requestMethodContextKey{}, method)
+	if isSafeMethod(method) || idempotencyKeyFromContext(ctx) != "" {
+		return ctx, nil
+	}
+
+	key, err := c.nextIdempotencyKey()
+	if err != nil {
+		return nil, err
+	}
+	return WithIdempotencyKey(ctx, key), nil
+}
+
+// nextIdempotencyKey returns the key set with SetIdempotencyKey for the
+// first write and "<key>-2", "<key>-3", ... for later ones, so re-running
+// the same command reproduces the same keys. Without a key set, a random
+// one is generated per write.
+func (c *Client) nextIdempotencyKey() (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.idempotencyKey == "" {
+		key, err := newUUID()
+		if err != nil {
+			return "", fmt.Errorf("generate idempotency key: %w", err)
+		}
+		return key, nil
+	}
+
+	c.writes++
+	if c.writes == 1 {
+		return c.idempotencyKey, nil
+	}
+	return fmt.Sprintf("%s-%d", c.idempotencyKey, c.writes), nil
+}
+
+// IdempotentRetryPolicy is retryablehttp's default policy, except that
+// requests with unsafe methods (anything but GET, HEAD and OPTIONS) are only
+// retried when they carry an idempotency key.
+func IdempotentRetryPolicy(ctx context.Context, resp *http.Response, err error) (bool, error) {
+	if ctx.Err() != nil {
+		return false, ctx.Err()
+	}
+
+	if !retryAllowed(ctx, resp) {
+		return false, nil
+	}
+
+	return retryablehttp.DefaultRetryPolicy(ctx, resp, err)
+}
+
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+// newUUID returns a random UUID (version 4).
+func newUUID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
@@ -34,6 +34,9 @@ func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error)
 		zap.String("url", req.URL.String()),
 		zap.Duration("duration", duration),
 	}
+	if id := req.Header.Get(headerRequestID); id != "" {
+		fields = append(fields, zap.String("requestId", id))
+	}
 
 	if err != nil {
 		t.logger.Error("API request failed", append(fields, zap.Error(err))...)
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"context"
+
+	"github.com/EnSync-engine/CLI/app/domain"
+)
+
+// PageFetcher returns one page of results along with the total result count.
+type PageFetcher[T any] func(ctx context.Context, params *ListParams) ([]T, int, error)
+
+// WalkPages calls visit with the results of each page of a list endpoint in
+// turn, until the last page or until visit returns false. Pages are
+// requested at the largest size the server allows; params, if not nil,
+// supplies the order and filters.
+func WalkPages[T any](ctx context.Context, params *ListParams, fetch PageFetcher[T], visit func(results []T) bool) error {
+	page := DefaultListParams()
+	if params != nil {
+		copied := *params
+		page = &copied
+	}
+	page.PageIndex = 0
+	page.Limit = maxListLimit
+
+	for seen := 0; ; page.PageIndex++ {
+		results, total, err := fetch(ctx, page)
+		if err != nil {
+			return err
+		}
+		if !visit(results) {
+			return nil
+		}
+
+		seen += len(results)
+		if len(results) == 0 || len(results) < page.Limit || seen >= total {
+			return nil
+		}
+	}
+}
+
+// CollectPages walks every page of a list endpoint and returns all results.
+func CollectPages[T any](ctx context.Context, params *ListParams, fetch PageFetcher[T]) ([]T, error) {
+	var all []T
+	err := WalkPages(ctx, params, fetch, func(results []T) bool {
+		all = append(all, results...)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return all, nil
+}
+
+// EventPages is ListEvents as a PageFetcher.
+func EventPages(client EventService) PageFetcher[*domain.Event] {
+	return func(ctx context.Context, params *ListParams) ([]*domain.Event, int, error) {
+		list, err := client.ListEvents(ctx, params)
+		if err != nil {
+			return nil, 0, err
+		}
+		return list.Results, list.ResultsLength, nil
+	}
+}
+
+// AccessKeyPages is ListAccessKeys as a PageFetcher.
+func AccessKeyPages(client AccessKeyService) PageFetcher[*domain.AccessKeyPermissions] {
+	return func(ctx context.Context, params *ListParams) ([]*domain.AccessKeyPermissions, int, error) {
+		list, err := client.ListAccessKeys(ctx, params)
+		if err != nil {
+			return nil, 0, err
+		}
+		return list.Results, list.ResultsLength, nil
+	}
+}
+
+// WorkspacePages is ListWorkspaces as a PageFetcher.
+func WorkspacePages(client WorkspaceService) PageFetcher[*domain.Workspace] {
+	return func(ctx context.Context, params *ListParams) ([]*domain.Workspace, int, error) {
+		list, err := client.ListWorkspaces(ctx, params)
+		if err != nil {
+			return nil, 0, err
+		}
+		return list.Results, list.ResultsLength, nil
+	}
+}
@@ -5,6 +5,9 @@ import (
 	"net/url"
 )
 
+// maxListLimit is the largest page the server returns.
+const maxListLimit = 100
+
 type ListParams struct {
 	PageIndex int               `validate:"gte=0"`
 	Limit     int               `validate:"gt=0,lte=100"`
@@ -40,8 +43,8 @@ func (p *ListParams) Validate() error {
 	if p.PageIndex < 0 {
 		return fmt.Errorf("pageIndex must be >= 0, got %d", p.PageIndex)
 	}
-	if p.Limit <= 0 || p.Limit > 100 {
-		return fmt.Errorf("limit must be between 1 and 100, got %d", p.Limit)
+	if p.Limit <= 0 || p.Limit > maxListLimit {
+		return fmt.Errorf("limit must be between 1 and %d, got %d", maxListLimit, p.Limit)
 	}
 	if p.Order != "ASC" && p.Order != "DESC" && p.Order != "asc" && p.Order != "desc" {
 		return fmt.Errorf("order must be ASC or DESC, got %s", p.Order)
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// ProbeResult describes how the server answered an unauthenticated request
+// for the base URL.
+type ProbeResult struct {
+	StatusCode int
+	Latency    time.Duration
+	// ServerTime is the server's Date header, zero if it sent none.
+	ServerTime time.Time
+	// ServerVersion is the server's X-EnSync-Version header.
+	ServerVersion string
+	// TLS is the connection state of HTTPS connections.
+	TLS *tls.ConnectionState
+	// InsecureSkipVerify is set when the server's certificate was not
+	// verified.
+	InsecureSkipVerify bool
+	// Proxy is the proxy the request went through, if any.
+	Proxy *url.URL
+}
+
+// Probe sends a single GET request for the base URL, bypassing retries,
+// rate limiting and the cache, to check that the server can be reached.
+// Any response counts as reached, whatever its status.
+func (c *Client) Probe(ctx context.Context) (*ProbeResult, error) {
+	transport := c.connectionTransport()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	result := &ProbeResult{}
+	if t, ok := transport.(*http.Transport); ok {
+		result.InsecureSkipVerify = t.TLSClientConfig != nil && t.TLSClientConfig.InsecureSkipVerify
+		if t.Proxy != nil {
+			proxy, err := t.Proxy(req)
+			if err != nil {
+				return nil, fmt.Errorf("resolve proxy: %w", err)
+			}
+			result.Proxy = proxy
+		}
+	}
+
+	start := time.Now()
+	resp, err := (&http.Client{Transport: transport}).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	result.StatusCode = resp.StatusCode
+	result.Latency = time.Since(start)
+	result.ServerVersion = resp.Header.Get(headerServerVersion)
+	result.TLS = resp.TLS
+	if date, err := http.ParseTime(resp.Header.Get("Date")); err == nil {
+		result.ServerTime = date
+	}
+	return result, nil
+}
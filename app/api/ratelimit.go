@@ -0,0 +1,256 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	headerRateLimitLimit     = "X-RateLimit-Limit"
+	headerRateLimitRemaining = "X-RateLimit-Remaining"
+	headerRateLimitReset     = "X-RateLimit-Reset"
+
+	// defaultThrottle is how long a 429 response without Retry-After or
+	// X-RateLimit-Reset pauses requests.
+	defaultThrottle = time.Second
+
+	// epochResetThreshold separates X-RateLimit-Reset values given as a Unix
+	// timestamp from those given in seconds until the reset.
+	epochResetThreshold = 1_000_000_000
+)
+
+// RateLimiter paces requests to the server. It starts at a static rate and
+// adapts to the server's X-RateLimit-* headers and 429 responses: the rate
+// is lowered to spread the remaining quota over the rest of the window,
+// bursts are kept within the server's limit, and all requests are paused
+// until the window resets once it is exhausted. The static rate and burst
+// are restored when the window resets, whether or not later responses
+// report it. One RateLimiter is shared by every request a client makes, so
+// concurrent workers draw from the same quota.
+type RateLimiter struct {
+	limiter *rate.Limiter
+	limit   rate.Limit
+	burst   int
+
+	mu          sync.Mutex
+	pausedUntil time.Time
+	restoreAt   time.Time
+	onThrottle  func(wait time.Duration)
+	quota       *RateLimitQuota
+}
+
+// RateLimitQuota is the server's rate limit as last reported in the
+// X-RateLimit-* headers.
+type RateLimitQuota struct {
+	Limit     int       `json:"limit"`
+	Remaining int       `json:"remaining"`
+	Reset     time.Time `json:"reset"`
+}
+
+// NewRateLimiter returns a limiter allowing rps requests per second with
+// bursts of up to burst requests. The server can only lower the rate.
+func NewRateLimiter(rps float64, burst int) *RateLimiter {
+	return &RateLimiter{
+		limiter: rate.NewLimiter(rate.Limit(rps), burst),
+		limit:   rate.Limit(rps),
+		burst:   burst,
+	}
+}
+
+// Limit returns the current rate in requests per second.
+func (l *RateLimiter) Limit() float64 {
+	l.restoreAfterReset(time.Now())
+	return float64(l.limiter.Limit())
+}
+
+// Burst returns the current maximum burst size.
+func (l *RateLimiter) Burst() int {
+	l.restoreAfterReset(time.Now())
+	return l.limiter.Burst()
+}
+
+// Quota returns the rate limit last reported by the server, if any.
+func (l *RateLimiter) Quota() (RateLimitQuota, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.quota == nil {
+		return RateLimitQuota{}, false
+	}
+	return *l.quota, true
+}
+
+// Wait blocks until a request may be sent or ctx is done.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	l.restoreAfterReset(time.Now())
+
+	l.mu.Lock()
+	wait := time.Until(l.pausedUntil)
+	l.mu.Unlock()
+
+	if wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	return l.limiter.Wait(ctx)
+}
+
+// Observe adapts the limiter to the rate limit state reported in resp.
+func (l *RateLimiter) Observe(resp *http.Response) {
+	now := time.Now()
+	reset, hasReset := parseRateLimitReset(resp.Header.Get(headerRateLimitReset), now)
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		wait, ok := throttleWait(resp.Header)
+		if !ok {
+			wait = defaultThrottle
+		}
+		l.pauseUntil(now.Add(min(wait, maxRetryAfter)))
+		return
+	}
+
+	remaining, err := strconv.Atoi(resp.Header.Get(headerRateLimitRemaining))
+	if err != nil || !hasReset {
+		return
+	}
+	limit, err := strconv.Atoi(resp.Header.Get(headerRateLimitLimit))
+	if err != nil || limit <= 0 {
+		limit = 0
+	}
+	l.mu.Lock()
+	l.quota = &RateLimitQuota{Limit: limit, Remaining: remaining, Reset: reset}
+	l.mu.Unlock()
+
+	window := reset.Sub(now)
+	if window <= 0 {
+		l.restore()
+		return
+	}
+	if remaining <= 0 {
+		l.pauseUntil(now.Add(min(window, maxRetryAfter)))
+		return
+	}
+
+	burst := l.burst
+	if limit > 0 {
+		// No more than the whole window's quota can be sent at once.
+		remaining = min(remaining, limit)
+		burst = min(burst, limit)
+	}
+	l.mu.Lock()
+	l.restoreAt = reset
+	l.mu.Unlock()
+	l.limiter.SetLimit(min(rate.Limit(float64(remaining)/window.Seconds()), l.limit))
+	l.limiter.SetBurst(burst)
+}
+
+// restoreAfterReset restores the static rate once the window it was lowered
+// for has reset.
+func (l *RateLimiter) restoreAfterReset(now time.Time) {
+	l.mu.Lock()
+	due := !l.restoreAt.IsZero() && !now.Before(l.restoreAt)
+	l.mu.Unlock()
+	if due {
+		l.restore()
+	}
+}
+
+func (l *RateLimiter) restore() {
+	l.mu.Lock()
+	l.restoreAt = time.Time{}
+	l.mu.Unlock()
+	l.limiter.SetLimit(l.limit)
+	l.limiter.SetBurst(l.burst)
+}
+
+// pauseUntil holds back requests until the given time, calling onThrottle
+// when a new pause starts. Concurrent workers hitting the same exhausted
+// window only extend the pause.
+func (l *RateLimiter) pauseUntil(until time.Time) {
+	l.mu.Lock()
+	now := time.Now()
+	if !until.After(l.pausedUntil) || !until.After(now) {
+		l.mu.Unlock()
+		return
+	}
+	started := !l.pausedUntil.After(now)
+	l.pausedUntil = until
+	notify := l.onThrottle
+	l.mu.Unlock()
+
+	if started && notify != nil {
+		notify(until.Sub(now))
+	}
+}
+
+func (l *RateLimiter) setThrottleHandler(handler func(wait time.Duration)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.onThrottle = handler
+}
+
+// throttleWait returns how long a 429 response asks the client to wait,
+// from its Retry-After or X-RateLimit-Reset header.
+func throttleWait(header http.Header) (time.Duration, bool) {
+	if wait, ok := parseRetryAfter(header.Get(headerRetryAfter)); ok {
+		return wait, true
+	}
+	now := time.Now()
+	if reset, ok := parseRateLimitReset(header.Get(headerRateLimitReset), now); ok {
+		return max(reset.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// parseRateLimitReset parses an X-RateLimit-Reset value given either as a
+// Unix timestamp or in seconds from now.
+func parseRateLimitReset(value string, now time.Time) (time.Time, bool) {
+	seconds, err := strconv.ParseFloat(value, 64)
+	if err != nil || seconds < 0 {
+		return time.Time{}, false
+	}
+	if seconds >= epochResetThreshold {
+		return time.Unix(0, int64(seconds*float64(time.Second))), true
+	}
+	return now.Add(time.Duration(seconds * float64(time.Second))), true
+}
+
+type rateLimitTransport struct {
+	next    http.RoundTripper
+	limiter *RateLimiter
+}
+
+// NewRateLimitMiddleware makes every request wait for limiter and feeds
+// the responses back to it. It sits below the retrying client, so retries
+// are paced too.
+func NewRateLimitMiddleware(limiter *RateLimiter) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return &rateLimitTransport{
+			next:    next,
+			limiter: limiter,
+		}
+	}
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.limiter.Wait(req.Context()); err != nil {
+		return nil, fmt.Errorf("wait for rate limit: %w", err)
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	t.limiter.Observe(resp)
+	return resp, nil
+}
@@ -30,16 +30,21 @@ func marshalBody(body any) (io.Reader, error) {
 	return bytes.NewReader(bodyBytes), nil
 }
 
-func buildRequest(ctx context.Context, method, reqURL string, body io.Reader, accessKey string) (*http.Request, error) {
+func buildRequest(ctx context.Context, method, reqURL string, body io.Reader, contentType string) (*http.Request, error) {
 	req, err := http.NewRequestWithContext(ctx, method, reqURL, body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set(headerAccessKey, accessKey)
 	req.Header.Set(headerAccept, contentTypeJSON)
 	if body != nil {
-		req.Header.Set(headerContentType, contentTypeJSON)
+		req.Header.Set(headerContentType, contentType)
+	}
+	if id := requestIDFromContext(ctx); id != "" {
+		req.Header.Set(headerRequestID, id)
+	}
+	if key := idempotencyKeyFromContext(ctx); key != "" && !isSafeMethod(method) {
+		req.Header.Set(headerIdempotencyKey, key)
 	}
 
 	return req, nil
@@ -0,0 +1,41 @@
+package api
+
+import (
+	"context"
+	"fmt"
+)
+
+type requestIDContextKey struct{}
+
+// WithRequestID returns a context whose requests carry id in the
+// X-Request-ID header instead of a generated one.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey{}, id)
+}
+
+func requestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDContextKey{}).(string)
+	return id
+}
+
+// withRequestID gives the request in ctx its ID: the one set with
+// WithRequestID or SetRequestID, or a random one. Retries reuse the
+// request and so keep the ID, letting the server's logs for every attempt
+// be matched to the one operation.
+func (c *Client) withRequestID(ctx context.Context) (context.Context, error) {
+	if requestIDFromContext(ctx) != "" {
+		return ctx, nil
+	}
+
+	c.mu.Lock()
+	id := c.requestID
+	c.mu.Unlock()
+
+	if id == "" {
+		var err error
+		if id, err = newUUID(); err != nil {
+			return nil, fmt.Errorf("generate request ID: %w", err)
+		}
+	}
+	return WithRequestID(ctx, id), nil
+}
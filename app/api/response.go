@@ -2,11 +2,67 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 )
 
+// APIError is returned when the server answers with an error status.
+type APIError struct {
+	StatusCode int
+	Body       string
+	// RequestID identifies the request in the server's logs.
+	RequestID string
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("request failed with status %d: %s", e.StatusCode, e.Body)
+	if e.RequestID != "" {
+		msg += fmt.Sprintf(" (request ID %s)", e.RequestID)
+	}
+	return msg
+}
+
+// RequestError is returned when a request got no response from the server.
+type RequestError struct {
+	RequestID string
+	Err       error
+}
+
+func (e *RequestError) Error() string {
+	return fmt.Sprintf("http request failed (request ID %s): %v", e.RequestID, e.Err)
+}
+
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+// RequestID returns the X-Request-ID of the failed request err wraps, if
+// any.
+func RequestID(err error) string {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.RequestID
+	}
+	var reqErr *RequestError
+	if errors.As(err, &reqErr) {
+		return reqErr.RequestID
+	}
+	return ""
+}
+
+// ErrNotFound is returned when a lookup the client does itself, rather
+// than the server, finds nothing.
+var ErrNotFound = errors.New("not found")
+
+// IsNotFound reports whether err wraps ErrNotFound or an APIError with
+// status 404.
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.Is(err, ErrNotFound) || errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
 func readResponseBody(resp *http.Response) ([]byte, error) {
 	if resp == nil || resp.Body == nil {
 		return nil, fmt.Errorf("invalid response: nil response or body")
@@ -19,8 +75,8 @@ func readResponseBody(resp *http.Response) ([]byte, error) {
 	return body, nil
 }
 
-func handleErrorResponse(statusCode int, body []byte) error {
-	return fmt.Errorf("request failed with status %d: %s", statusCode, string(body))
+func handleErrorResponse(statusCode int, body []byte, requestID string) error {
+	return &APIError{StatusCode: statusCode, Body: string(body), RequestID: requestID}
 }
 
 func unmarshalResponse[T any](data []byte, target *T) error {
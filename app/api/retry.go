@@ -0,0 +1,226 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+const (
+	headerRetryAfter = "Retry-After"
+
+	// maxRetryAfter caps how long a Retry-After header can make the client
+	// wait before the next attempt.
+	maxRetryAfter = time.Minute
+)
+
+type Backoff string
+
+const (
+	BackoffExponential       Backoff = "exponential"
+	BackoffExponentialJitter Backoff = "exponential-jitter"
+	BackoffConstant          Backoff = "constant"
+)
+
+// ParseBackoff returns the named backoff strategy.
+func ParseBackoff(name string) (Backoff, error) {
+	switch backoff := Backoff(name); backoff {
+	case BackoffExponential, BackoffExponentialJitter, BackoffConstant:
+		return backoff, nil
+	default:
+		return "", fmt.Errorf("unknown backoff %q: use exponential, exponential-jitter or constant", name)
+	}
+}
+
+// RetryPolicy controls how failed requests are retried. Connection errors
+// and responses with one of StatusCodes are retried up to MaxRetries times,
+// waiting between WaitMin and WaitMax. Requests with unsafe methods are only
+// retried when they carry an idempotency key.
+type RetryPolicy struct {
+	MaxRetries  int
+	WaitMin     time.Duration
+	WaitMax     time.Duration
+	Backoff     Backoff
+	StatusCodes []int
+
+	// RespectRetryAfter makes 429 and 503 responses wait for the duration
+	// in their Retry-After header, up to a minute, instead of backing off.
+	// A 429 without Retry-After waits for its X-RateLimit-Reset instead.
+	RespectRetryAfter bool
+}
+
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries: 3,
+		WaitMin:    1 * time.Second,
+		WaitMax:    5 * time.Second,
+		Backoff:    BackoffExponentialJitter,
+		StatusCodes: []int{
+			http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+		RespectRetryAfter: true,
+	}
+}
+
+// apply configures the retrying client to follow the policy, logging each
+// retry at debug level and recording it on the request's span.
+func (p RetryPolicy) apply(client *retryablehttp.Client, logger *zap.Logger) {
+	client.RetryMax = p.MaxRetries
+	client.RetryWaitMin = p.WaitMin
+	client.RetryWaitMax = p.WaitMax
+	client.CheckRetry = p.checkRetry
+	client.Backoff = p.backoff
+	client.ErrorHandler = lastResponseErrorHandler
+	client.RequestLogHook = func(_ retryablehttp.Logger, req *http.Request, attempt int) {
+		if attempt == 0 {
+			return
+		}
+		fields := []zap.Field{
+			zap.String("method", req.Method),
+			zap.String("url", req.URL.String()),
+			zap.Int("attempt", attempt),
+			zap.Int("maxRetries", p.MaxRetries),
+		}
+		if id := req.Header.Get(headerRequestID); id != "" {
+			fields = append(fields, zap.String("requestId", id))
+		}
+		attrs := []attribute.KeyValue{attribute.Int("attempt", attempt)}
+		if state := retryStateFromContext(req.Context()); state != nil {
+			waited := time.Since(state.failedAt)
+			fields = append(fields, zap.String("reason", state.reason), zap.Duration("waited", waited))
+			attrs = append(attrs, attribute.String("reason", state.reason), attribute.Float64("waited_seconds", waited.Seconds()))
+		}
+		logger.Debug("Retrying API request", fields...)
+		trace.SpanFromContext(req.Context()).AddEvent("retry", trace.WithAttributes(attrs...))
+	}
+}
+
+func (p RetryPolicy) checkRetry(ctx context.Context, resp *http.Response, err error) (bool, error) {
+	if ctx.Err() != nil {
+		return false, ctx.Err()
+	}
+	if !retryAllowed(ctx, resp) {
+		return false, nil
+	}
+
+	var reason string
+	switch {
+	case IsCircuitOpen(err):
+		return false, nil
+	case err != nil:
+		// The default policy rules out errors that will not go away, such
+		// as TLS verification failures and redirect loops.
+		if retry, _ := retryablehttp.DefaultRetryPolicy(ctx, nil, err); !retry {
+			return false, nil
+		}
+		reason = err.Error()
+	case slices.Contains(p.StatusCodes, resp.StatusCode):
+		reason = resp.Status
+	default:
+		return false, nil
+	}
+
+	if state := retryStateFromContext(ctx); state != nil {
+		state.reason = reason
+		state.failedAt = time.Now()
+	}
+	return true, nil
+}
+
+func (p RetryPolicy) backoff(waitMin, waitMax time.Duration, attempt int, resp *http.Response) time.Duration {
+	if p.RespectRetryAfter && resp != nil {
+		switch resp.StatusCode {
+		case http.StatusTooManyRequests:
+			if wait, ok := throttleWait(resp.Header); ok {
+				return min(wait, maxRetryAfter)
+			}
+		case http.StatusServiceUnavailable:
+			if wait, ok := parseRetryAfter(resp.Header.Get(headerRetryAfter)); ok {
+				return min(wait, maxRetryAfter)
+			}
+		}
+	}
+
+	if p.Backoff == BackoffConstant || waitMin <= 0 {
+		return waitMin
+	}
+
+	wait := waitMax
+	if attempt < 32 {
+		wait = min(waitMin<<attempt, waitMax)
+	}
+	if p.Backoff == BackoffExponentialJitter && wait > 1 {
+		// Equal jitter: keep half the wait and randomize the rest, so
+		// concurrent workers do not retry in lockstep.
+		wait = wait/2 + rand.N(wait/2)
+	}
+	return wait
+}
+
+// parseRetryAfter parses a Retry-After value given in seconds or as an
+// HTTP date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(max(seconds, 0)) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+	return 0, false
+}
+
+// lastResponseErrorHandler returns the last response once retries are
+// exhausted, so its status and body reach the caller as an APIError rather
+// than a generic "giving up" error.
+func lastResponseErrorHandler(resp *http.Response, err error, attempts int) (*http.Response, error) {
+	if resp != nil {
+		return resp, nil
+	}
+	return nil, fmt.Errorf("giving up after %d attempt(s): %w", attempts, err)
+}
+
+// retryAllowed reports whether the request may be retried: safe methods
+// always, unsafe ones only with an idempotency key.
+func retryAllowed(ctx context.Context, resp *http.Response) bool {
+	method, _ := ctx.Value(requestMethodContextKey{}).(string)
+	hasKey := idempotencyKeyFromContext(ctx) != ""
+	if resp != nil && resp.Request != nil {
+		method = resp.Request.Method
+		hasKey = resp.Request.Header.Get(headerIdempotencyKey) != ""
+	}
+	return method == "" || isSafeMethod(method) || hasKey
+}
+
+// retryState carries why the previous attempt of a request failed to the
+// retry log hook.
+type retryState struct {
+	reason   string
+	failedAt time.Time
+}
+
+type retryStateContextKey struct{}
+
+func withRetryState(ctx context.Context) context.Context {
+	return context.WithValue(ctx, retryStateContextKey{}, &retryState{})
+}
+
+func retryStateFromContext(ctx context.Context) *retryState {
+	state, _ := ctx.Value(retryStateContextKey{}).(*retryState)
+	return state
+}
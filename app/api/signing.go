@@ -0,0 +1,42 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/EnSync-engine/CLI/app/domain"
+	"github.com/EnSync-engine/CLI/pkg/signing"
+)
+
+type signingTransport struct {
+	next          http.RoundTripper
+	keyPair       domain.ServiceKeyPair
+	omitAccessKey bool
+}
+
+// NewSigningMiddleware signs each request with the service key pair, so the
+// server can reject altered and replayed requests; see package signing. It
+// must see every attempt, since each gets its own timestamp and nonce. With
+// omitAccessKey, the X-ACCESS-KEY header is removed before signing, so the
+// access key never leaves the machine.
+func NewSigningMiddleware(keyPair domain.ServiceKeyPair, omitAccessKey bool) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return &signingTransport{
+			next:          next,
+			keyPair:       keyPair,
+			omitAccessKey: omitAccessKey,
+		}
+	}
+}
+
+func (t *signingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	signed := req.Clone(req.Context())
+	if t.omitAccessKey {
+		signed.Header.Del(headerAccessKey)
+	}
+	if err := signing.Sign(signed, t.keyPair.PublicKey, t.keyPair.PrivateKey, time.Now()); err != nil {
+		return nil, fmt.Errorf("sign request: %w", err)
+	}
+	return t.next.RoundTrip(signed)
+}
@@ -0,0 +1,140 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	instrumentationName = "github.com/EnSync-engine/CLI/app/api"
+
+	attributeEndpoint  = attribute.Key("ensync.endpoint")
+	attributeRequestID = attribute.Key("ensync.request_id")
+
+	// redacted replaces credentials in exported URLs, as the OpenTelemetry
+	// semantic conventions suggest.
+	redacted = "REDACTED"
+)
+
+type telemetryTransport struct {
+	next       http.RoundTripper
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+	requests   metric.Int64Counter
+	duration   metric.Float64Histogram
+	basePath   string
+}
+
+// NewTelemetryMiddleware records a client span per request, propagated to
+// the server in the W3C traceparent header, along with request count and
+// latency metrics. Retries of the request are added to its span as events.
+// baseURL is used to tell which endpoint a request addresses.
+func NewTelemetryMiddleware(tracerProvider trace.TracerProvider, meterProvider metric.MeterProvider, baseURL string) Middleware {
+	meter := meterProvider.Meter(instrumentationName)
+	// Instrument errors only report invalid names; the instruments
+	// returned alongside them are usable no-ops.
+	requests, _ := meter.Int64Counter("ensync.client.requests",
+		metric.WithDescription("Number of API requests sent to the EnSync server."),
+		metric.WithUnit("{request}"),
+	)
+	duration, _ := meter.Float64Histogram("http.client.request.duration",
+		metric.WithDescription("Duration of API requests, including retries."),
+		metric.WithUnit("s"),
+	)
+	basePath := basePathOf(baseURL)
+
+	return func(next http.RoundTripper) http.RoundTripper {
+		return &telemetryTransport{
+			next:       next,
+			tracer:     tracerProvider.Tracer(instrumentationName),
+			propagator: propagation.TraceContext{},
+			requests:   requests,
+			duration:   duration,
+			basePath:   basePath,
+		}
+	}
+}
+
+func (t *telemetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	endpoint := resourceOf(t.basePath, req.URL)
+	attrs := []attribute.KeyValue{
+		semconv.HTTPRequestMethodKey.String(req.Method),
+		semconv.ServerAddress(req.URL.Hostname()),
+		attributeEndpoint.String(endpoint),
+	}
+
+	ctx, span := t.tracer.Start(req.Context(), req.Method+" "+endpoint,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+		trace.WithAttributes(
+			semconv.URLFull(redactURL(t.basePath, req.URL)),
+			attributeRequestID.String(req.Header.Get(headerRequestID)),
+		),
+	)
+	defer span.End()
+
+	// The request belongs to the caller, so the header is set on a copy.
+	req = req.Clone(ctx)
+	t.propagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	elapsed := time.Since(start)
+
+	switch {
+	case err != nil:
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		attrs = append(attrs, semconv.ErrorTypeKey.String("request"))
+	case resp.StatusCode >= http.StatusBadRequest:
+		span.SetStatus(codes.Error, resp.Status)
+		attrs = append(attrs,
+			semconv.HTTPResponseStatusCode(resp.StatusCode),
+			semconv.ErrorTypeKey.String(strconv.Itoa(resp.StatusCode)),
+		)
+	default:
+		attrs = append(attrs, semconv.HTTPResponseStatusCode(resp.StatusCode))
+	}
+	if resp != nil {
+		span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
+	}
+
+	set := metric.WithAttributes(attrs...)
+	t.requests.Add(ctx, 1, set)
+	t.duration.Record(ctx, elapsed.Seconds(), set)
+
+	return resp, err
+}
+
+// redactURL returns u for export with the access key of /access-key/{key}
+// paths and the accessKey filter of access key lists redacted.
+func redactURL(basePath string, u *url.URL) string {
+	if resourceOf(basePath, u) != "access-key" {
+		return u.String()
+	}
+
+	clean := *u
+	clean.User = nil
+	clean.RawPath = ""
+	rest := strings.TrimPrefix(strings.TrimPrefix(u.Path, basePath), "/")
+	if segments := strings.Split(rest, "/"); len(segments) > 1 && segments[1] != "" {
+		segments[1] = redacted
+		clean.Path = basePath + "/" + strings.Join(segments, "/")
+	}
+	query := u.Query()
+	if query.Has("accessKey") {
+		query.Set("accessKey", redacted)
+		clean.RawQuery = query.Encode()
+	}
+	return clean.String()
+}
@@ -0,0 +1,134 @@
+package api
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// ProxyFunc picks the proxy for a request, like http.Transport.Proxy.
+type ProxyFunc func(*http.Request) (*url.URL, error)
+
+// TLSOptions configure how the client verifies the server and
+// authenticates itself to it.
+type TLSOptions struct {
+	// CAFile is a PEM bundle of certificate authorities trusted in
+	// addition to the system's.
+	CAFile string
+	// CertFile and KeyFile hold a PEM client certificate and its key for
+	// mutual TLS.
+	CertFile string
+	KeyFile  string
+	// MinVersion is the lowest TLS version accepted, e.g. tls.VersionTLS13.
+	// Zero keeps Go's default.
+	MinVersion uint16
+	// InsecureSkipVerify accepts any server certificate. It makes the
+	// connection open to interception and is meant for testing only.
+	InsecureSkipVerify bool
+}
+
+// NewTLSConfig loads the files named in opts into a TLS configuration.
+func NewTLSConfig(opts TLSOptions) (*tls.Config, error) {
+	cfg := &tls.Config{
+		MinVersion:         opts.MinVersion,
+		InsecureSkipVerify: opts.InsecureSkipVerify,
+	}
+
+	if opts.CAFile != "" {
+		pem, err := os.ReadFile(opts.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read CA bundle %q: %w", opts.CAFile, err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("read CA bundle %q: no PEM certificates found", opts.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	switch {
+	case opts.CertFile != "" && opts.KeyFile != "":
+		cert, err := tls.LoadX509KeyPair(opts.CertFile, opts.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load client certificate %q: %w", opts.CertFile, err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	case opts.CertFile != "" || opts.KeyFile != "":
+		return nil, errors.New("a client certificate needs both a certificate and a key file")
+	}
+
+	return cfg, nil
+}
+
+// ParseTLSVersion parses a TLS version such as "1.2".
+func ParseTLSVersion(version string) (uint16, error) {
+	switch version {
+	case "1.0":
+		return tls.VersionTLS10, nil
+	case "1.1":
+		return tls.VersionTLS11, nil
+	case "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("unknown TLS version %q: use 1.0, 1.1, 1.2 or 1.3", version)
+	}
+}
+
+// NewProxyFunc returns a ProxyFunc sending requests through proxyURL, an
+// http, https, socks5 or socks5h URL, except for hosts matched by noProxy
+// (a NO_PROXY style list). An empty proxyURL or noProxy falls back to the
+// HTTP_PROXY/HTTPS_PROXY and NO_PROXY environment variables. Requests to
+// localhost are never proxied.
+func NewProxyFunc(proxyURL, noProxy string) (ProxyFunc, error) {
+	cfg := httpproxy.FromEnvironment()
+
+	if proxyURL != "" {
+		parsed, err := url.Parse(proxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("parse proxy URL: %w", err)
+		}
+		switch parsed.Scheme {
+		case "http", "https", "socks5", "socks5h":
+		default:
+			return nil, fmt.Errorf("unsupported proxy scheme %q: use http, https, socks5 or socks5h", parsed.Scheme)
+		}
+		cfg.HTTPProxy = proxyURL
+		cfg.HTTPSProxy = proxyURL
+	}
+	if noProxy != "" {
+		cfg.NoProxy = noProxy
+	}
+
+	proxy := cfg.ProxyFunc()
+	return func(req *http.Request) (*url.URL, error) {
+		return proxy(req.URL)
+	}, nil
+}
+
+// connTransport returns the transport that opens connections to the
+// server, with the client's TLS and proxy settings applied.
+func (c *Client) connTransport() http.RoundTripper {
+	if c.tlsConfig == nil && c.proxy == nil {
+		return c.baseTransport
+	}
+
+	transport := c.baseTransport.Clone()
+	if c.tlsConfig != nil {
+		transport.TLSClientConfig = c.tlsConfig.Clone()
+	}
+	if c.proxy != nil {
+		transport.Proxy = c.proxy
+	}
+	return transport
+}
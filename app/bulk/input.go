@@ -0,0 +1,188 @@
+// Package bulk reads batches of records from NDJSON or CSV input and runs
+// them through a worker pool.
+package bulk
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+type Format string
+
+const (
+	FormatNDJSON Format = "ndjson"
+	FormatCSV    Format = "csv"
+)
+
+// listSeparator splits list values in CSV cells, e.g. "a;b;c".
+const listSeparator = ";"
+
+// Record is one input row. Line is the 1-based line in the input. NDJSON
+// fields keep their JSON types; CSV fields are strings keyed by the header.
+type Record struct {
+	Line   int            `json:"line"`
+	Fields map[string]any `json:"fields"`
+}
+
+// ParseFormat returns the named format, or detects it from the file
+// extension when name is empty.
+func ParseFormat(name, path string) (Format, error) {
+	if name == "" {
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".ndjson", ".jsonl", ".json":
+			return FormatNDJSON, nil
+		case ".csv":
+			return FormatCSV, nil
+		default:
+			return "", fmt.Errorf("cannot detect format of %q: use --format ndjson or csv", path)
+		}
+	}
+
+	switch format := Format(strings.ToLower(name)); format {
+	case FormatNDJSON, FormatCSV:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unsupported format %q: use ndjson or csv", name)
+	}
+}
+
+// Read parses every record in r. Blank NDJSON lines are skipped.
+func Read(r io.Reader, format Format) ([]Record, error) {
+	switch format {
+	case FormatNDJSON:
+		return readNDJSON(r)
+	case FormatCSV:
+		return readCSV(r)
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+func readNDJSON(r io.Reader) ([]Record, error) {
+	var records []Record
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		var fields map[string]any
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return nil, fmt.Errorf("line %d: invalid JSON object: %w", line, err)
+		}
+		records = append(records, Record{Line: line, Fields: fields})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read input: %w", err)
+	}
+
+	return records, nil
+}
+
+func readCSV(r io.Reader) ([]Record, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read CSV header: %w", err)
+	}
+	for i := range header {
+		header[i] = strings.TrimSpace(header[i])
+	}
+
+	var records []Record
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read CSV: %w", err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		fields := make(map[string]any, len(header))
+		for i, name := range header {
+			if value := strings.TrimSpace(row[i]); value != "" {
+				fields[name] = value
+			}
+		}
+		records = append(records, Record{Line: line, Fields: fields})
+	}
+
+	return records, nil
+}
+
+// String returns the named field as a string.
+func (r Record) String(name string) (string, error) {
+	switch value := r.Fields[name].(type) {
+	case nil:
+		return "", nil
+	case string:
+		return value, nil
+	default:
+		return "", fmt.Errorf("%s must be a string", name)
+	}
+}
+
+// Object returns the named field as a JSON object. In CSV input the cell
+// holds the object as JSON text.
+func (r Record) Object(name string) (map[string]any, error) {
+	switch value := r.Fields[name].(type) {
+	case nil:
+		return nil, nil
+	case map[string]any:
+		return value, nil
+	case string:
+		var object map[string]any
+		if err := json.Unmarshal([]byte(value), &object); err != nil {
+			return nil, fmt.Errorf("%s is not a JSON object: %w", name, err)
+		}
+		return object, nil
+	default:
+		return nil, fmt.Errorf("%s must be a JSON object", name)
+	}
+}
+
+// List returns the named field as a list of strings. In CSV input the cell
+// holds the items separated by ";".
+func (r Record) List(name string) ([]string, error) {
+	switch value := r.Fields[name].(type) {
+	case nil:
+		return nil, nil
+	case string:
+		var items []string
+		for _, item := range strings.Split(value, listSeparator) {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		return items, nil
+	case []any:
+		items := make([]string, 0, len(value))
+		for _, item := range value {
+			str, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s must be a list of strings", name)
+			}
+			items = append(items, str)
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("%s must be a list of strings", name)
+	}
+}
@@ -0,0 +1,109 @@
+package bulk
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+type Status string
+
+const (
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+	StatusSkipped   Status = "skipped"
+)
+
+// Result is the outcome of one record. Output holds whatever the task
+// returned, e.g. a newly created access key.
+type Result struct {
+	Line   int    `json:"line"`
+	Name   string `json:"name,omitempty"`
+	Status Status `json:"status"`
+	Error  string `json:"error,omitempty"`
+	Output any    `json:"output,omitempty"`
+}
+
+// Task processes one record and returns the name to report it under and
+// its output.
+type Task func(ctx context.Context, record Record) (name string, output any, err error)
+
+// Runner runs a task for every record on a pool of workers. Requests made
+// by the task still pass through the API client's rate limiter, so the
+// pool size bounds concurrency rather than throughput.
+type Runner struct {
+	Workers         int
+	ContinueOnError bool
+
+	// OnResult, if set, is called once per record as results come in.
+	// Calls are serialized.
+	OnResult func(Result)
+}
+
+// Run processes records and returns their results in input order. Unless
+// ContinueOnError is set, the first failure stops the run: records already
+// in flight complete, and those that have not started are reported as
+// skipped. Cancelling ctx stops the run the same way.
+func (r *Runner) Run(ctx context.Context, records []Record, task Task) []Result {
+	workers := max(r.Workers, 1)
+	results := make([]Result, len(records))
+	indexes := make(chan int)
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		stopped atomic.Bool
+	)
+	report := func(i int, result Result) {
+		mu.Lock()
+		defer mu.Unlock()
+		results[i] = result
+		if r.OnResult != nil {
+			r.OnResult(result)
+		}
+	}
+
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				record := records[i]
+				if stopped.Load() || ctx.Err() != nil {
+					report(i, Result{Line: record.Line, Status: StatusSkipped})
+					continue
+				}
+
+				name, output, err := task(ctx, record)
+				result := Result{Line: record.Line, Name: name, Status: StatusSucceeded, Output: output}
+				if err != nil {
+					result.Status = StatusFailed
+					result.Error = err.Error()
+					if !r.ContinueOnError {
+						stopped.Store(true)
+					}
+				}
+				report(i, result)
+			}
+		}()
+	}
+
+	for i := range records {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return results
+}
+
+// Count returns how many results have the given status.
+func Count(results []Result, status Status) int {
+	n := 0
+	for _, result := range results {
+		if result.Status == status {
+			n++
+		}
+	}
+	return n
+}
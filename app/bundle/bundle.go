@@ -0,0 +1,164 @@
+// Package bundle encrypts secrets, such as rotated key pairs, with a
+// passphrase so they can be stored or handed over as a file.
+//
+// A bundle is a JSON document holding the AES-256-GCM encrypted contents
+// together with the PBKDF2-HMAC-SHA256 parameters that derive the key from
+// the passphrase.
+package bundle
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	format = "ensync-bundle-v1"
+	kdf    = "pbkdf2-sha256"
+
+	// iterations follows OWASP's recommendation for PBKDF2-HMAC-SHA256.
+	iterations = 600_000
+	saltSize   = 16
+	keySize    = 32
+
+	// MinPassphraseLength is the shortest passphrase Seal accepts.
+	MinPassphraseLength = 8
+)
+
+var (
+	// ErrWrongPassphrase is returned by Open when the passphrase does not
+	// decrypt the bundle, or the bundle was altered.
+	ErrWrongPassphrase = errors.New("wrong passphrase or corrupted bundle")
+	ErrShortPassphrase = fmt.Errorf("passphrase must be at least %d characters", MinPassphraseLength)
+)
+
+type envelope struct {
+	Format     string `json:"format"`
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// Sealer seals plaintexts with one key derived from a passphrase. Deriving
+// the key is deliberately slow, so callers sealing the same secrets again as
+// they grow, such as a bundle rewritten after every rotated key, derive it
+// once. Every bundle a Sealer makes shares its salt but has its own nonce.
+type Sealer struct {
+	salt []byte
+	aead cipher.AEAD
+}
+
+// NewSealer derives the key for passphrase with a new random salt.
+func NewSealer(passphrase string) (*Sealer, error) {
+	if len(passphrase) < MinPassphraseLength {
+		return nil, ErrShortPassphrase
+	}
+
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("generate salt: %w", err)
+	}
+	aead, err := newAEAD(passphrase, salt, iterations)
+	if err != nil {
+		return nil, err
+	}
+	return &Sealer{salt: salt, aead: aead}, nil
+}
+
+// Seal encrypts plaintext into a bundle.
+func (s *Sealer) Seal(plaintext []byte) ([]byte, error) {
+	env := envelope{Format: format, KDF: kdf, Iterations: iterations, Salt: s.salt, Nonce: make([]byte, s.aead.NonceSize())}
+	if _, err := rand.Read(env.Nonce); err != nil {
+		return nil, fmt.Errorf("generate nonce: %w", err)
+	}
+	env.Ciphertext = s.aead.Seal(nil, env.Nonce, plaintext, []byte(format))
+
+	return json.MarshalIndent(env, "", "  ")
+}
+
+// Seal encrypts plaintext with a key derived from passphrase.
+func Seal(plaintext []byte, passphrase string) ([]byte, error) {
+	sealer, err := NewSealer(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return sealer.Seal(plaintext)
+}
+
+// Open decrypts a bundle made by Seal.
+func Open(data []byte, passphrase string) ([]byte, error) {
+	var env envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, fmt.Errorf("decode bundle: %w", err)
+	}
+	switch {
+	case env.Format != format:
+		return nil, fmt.Errorf("unsupported bundle format %q", env.Format)
+	case env.KDF != kdf || env.Iterations < 1:
+		return nil, fmt.Errorf("unsupported key derivation %q with %d iterations", env.KDF, env.Iterations)
+	}
+
+	aead, err := newAEAD(passphrase, env.Salt, env.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	if len(env.Nonce) != aead.NonceSize() {
+		return nil, ErrWrongPassphrase
+	}
+	plaintext, err := aead.Open(nil, env.Nonce, env.Ciphertext, []byte(format))
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	return plaintext, nil
+}
+
+// WriteFile seals plaintext into the file at path, readable only by the
+// user. The file is replaced atomically, so an interrupted write keeps the
+// previous bundle intact.
+func WriteFile(path string, plaintext []byte, passphrase string) error {
+	data, err := Seal(plaintext, passphrase)
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("write bundle %q: %w", path, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("write bundle %q: %w", path, err)
+	}
+	return nil
+}
+
+// ReadFile opens the bundle at path.
+func ReadFile(path, passphrase string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read bundle %q: %w", path, err)
+	}
+	return Open(data, passphrase)
+}
+
+// DeriveKey derives the AES-256 key of a bundle from passphrase with
+// PBKDF2-HMAC-SHA256, as specified in RFC 8018 section 5.2.
+func DeriveKey(passphrase string, salt []byte, iterations int) []byte {
+	return pbkdf2.Key([]byte(passphrase), salt, iterations, keySize, sha256.New)
+}
+
+func newAEAD(passphrase string, salt []byte, iterations int) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(DeriveKey(passphrase, salt, iterations))
+	if err != nil {
+		return nil, fmt.Errorf("create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,159 @@
+// Package completion looks up the resource names offered by shell
+// completion, caching them on disk so that repeated tab presses do not
+// query the server each time.
+package completion
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/EnSync-engine/CLI/app/api"
+	"github.com/EnSync-engine/CLI/app/domain"
+)
+
+// Kind is the kind of resource whose names are completed.
+type Kind string
+
+const (
+	Events Kind = "events"
+	// AccessKeyIDs completes the IDs of access keys. The keys themselves
+	// are credentials, and are never completed.
+	AccessKeyIDs Kind = "access-key-ids"
+	Workspaces   Kind = "workspaces"
+)
+
+// CanAuthenticate reports whether auth gets its credentials without
+// running a command or contacting an identity provider, either of which
+// could prompt the user or stall the shell on every tab press.
+func CanAuthenticate(auth api.Authenticator) bool {
+	switch auth.(type) {
+	case nil, api.StaticKey, *api.KeyFileAuthenticator:
+		return true
+	default:
+		return false
+	}
+}
+
+// Key identifies a cached name list by the given parts, such as the
+// server, credentials, workspace scope and kind. Only its hash is stored.
+func Key(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// cacheEntry is the on-disk form of a cached name list.
+type cacheEntry struct {
+	FetchedAt time.Time `json:"fetchedAt"`
+	Names     []string  `json:"names"`
+}
+
+// Cache stores name lists under dir for ttl.
+type Cache struct {
+	dir string
+	ttl time.Duration
+}
+
+// NewCache returns a cache rooted at dir.
+func NewCache(dir string, ttl time.Duration) *Cache {
+	return &Cache{dir: dir, ttl: ttl}
+}
+
+// Names returns the names of kind stored under key when fresh, fetching
+// and storing them otherwise.
+func (c *Cache) Names(ctx context.Context, client api.APIClient, kind Kind, key string) ([]string, error) {
+	path := filepath.Join(c.dir, key+".json")
+
+	if data, err := os.ReadFile(path); err == nil {
+		var entry cacheEntry
+		if json.Unmarshal(data, &entry) == nil && time.Since(entry.FetchedAt) < c.ttl {
+			return entry.Names, nil
+		}
+	}
+
+	names, err := FetchNames(ctx, client, kind)
+	if err != nil {
+		return nil, err
+	}
+
+	// A failed cache write only costs a slower next completion.
+	if data, err := json.Marshal(cacheEntry{FetchedAt: time.Now(), Names: names}); err == nil {
+		if err := os.MkdirAll(c.dir, 0o700); err == nil {
+			_ = os.WriteFile(path, data, 0o600)
+		}
+	}
+	c.prune()
+
+	return names, nil
+}
+
+// prune removes expired entries, which are never read again.
+func (c *Cache) prune() {
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if info, err := entry.Info(); err == nil && time.Since(info.ModTime()) >= c.ttl {
+			_ = os.Remove(filepath.Join(c.dir, entry.Name()))
+		}
+	}
+}
+
+// Clear removes all cached names.
+func (c *Cache) Clear() error {
+	return os.RemoveAll(c.dir)
+}
+
+// FetchNames lists every name of kind from the server, sorted.
+func FetchNames(ctx context.Context, client api.APIClient, kind Kind) ([]string, error) {
+	var names []string
+
+	switch kind {
+	case Events:
+		events, err := api.CollectPages(ctx, nil, api.EventPages(client))
+		if err != nil {
+			return nil, err
+		}
+		for _, event := range events {
+			names = append(names, event.Name)
+		}
+	case AccessKeyIDs:
+		// Only IDs: the keys themselves are credentials, which must not be
+		// cached on disk or shown in the shell.
+		keys, err := api.CollectPages(ctx, nil, api.AccessKeyPages(client))
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range keys {
+			names = append(names, key.ID)
+		}
+	case Workspaces:
+		workspaces, err := api.CollectPages(ctx, nil, api.WorkspacePages(client))
+		if err != nil {
+			return nil, err
+		}
+		// Paths are derived from the parents when the server omits them.
+		var walk func(nodes []*domain.Workspace, parent string)
+		walk = func(nodes []*domain.Workspace, parent string) {
+			for _, ws := range nodes {
+				path := ws.Path
+				if path == "" {
+					path = strings.TrimPrefix(parent+"/"+ws.Name, "/")
+				}
+				names = append(names, path)
+				walk(ws.Children, path)
+			}
+		}
+		walk(domain.BuildWorkspaceTree(workspaces), "")
+	}
+
+	sort.Strings(names)
+	return names, nil
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -14,19 +15,229 @@ const (
 	envBaseURL   = "ENSYNC_BASE_URL"
 	envDebug     = "ENSYNC_DEBUG"
 	envConfigDir = "ENSYNC_CONFIG_DIR"
+	envProfile   = "ENSYNC_PROFILE"
+	envWorkspace = "ENSYNC_WORKSPACE"
+	envRetries   = "ENSYNC_RETRIES"
+
+	envOAuth2ClientSecret = "ENSYNC_OAUTH2_CLIENT_SECRET"
 
 	defaultConfigDirName = ".ensync"
 	configFileName       = "config"
 	configFileType       = "yaml"
 )
 
+// Settings can be set at the top level of the config file and overridden
+// per profile.
+type Settings struct {
+	BaseURL        string                 `mapstructure:"base_url"`
+	Workspace      string                 `mapstructure:"workspace"`
+	Retry          RetrySettings          `mapstructure:"retry"`
+	RateLimit      RateLimitSettings      `mapstructure:"rate_limit"`
+	CircuitBreaker CircuitBreakerSettings `mapstructure:"circuit_breaker"`
+	TLS            TLSSettings            `mapstructure:"tls"`
+	Proxy          ProxySettings          `mapstructure:"proxy"`
+	Auth           AuthSettings           `mapstructure:"auth"`
+	Signing        SigningSettings        `mapstructure:"signing"`
+}
+
+// SigningSettings turn on request signing with a service key pair.
+type SigningSettings struct {
+	// KeyPairFile is a JSON file holding the key pair, as printed by
+	// `ensync access-key rotate`.
+	KeyPairFile string `mapstructure:"key_pair_file"`
+	// OmitAccessKey stops sending the access key with signed requests; the
+	// key pair then identifies the caller.
+	OmitAccessKey *bool `mapstructure:"omit_access_key"`
+}
+
+// merge overrides s with the fields set in other.
+func (s *SigningSettings) merge(other SigningSettings) {
+	if other.KeyPairFile != "" {
+		s.KeyPairFile = other.KeyPairFile
+	}
+	if other.OmitAccessKey != nil {
+		s.OmitAccessKey = other.OmitAccessKey
+	}
+}
+
+// AuthSettings choose how requests are authenticated when no --access-key
+// is given. At most one mechanism may be set.
+type AuthSettings struct {
+	AccessKey  string         `mapstructure:"access_key"`
+	KeyFile    string         `mapstructure:"key_file"`
+	KeyCommand string         `mapstructure:"key_command"`
+	OAuth2     OAuth2Settings `mapstructure:"oauth2"`
+}
+
+// OAuth2Settings configure the OAuth2 client credentials grant.
+type OAuth2Settings struct {
+	TokenURL     string   `mapstructure:"token_url"`
+	ClientID     string   `mapstructure:"client_id"`
+	ClientSecret string   `mapstructure:"client_secret"`
+	Scopes       []string `mapstructure:"scopes"`
+}
+
+// IsSet reports whether o configures OAuth2.
+func (o OAuth2Settings) IsSet() bool {
+	return o.TokenURL != "" || o.ClientID != "" || o.ClientSecret != "" || len(o.Scopes) > 0
+}
+
+// IsSet reports whether a configures any mechanism.
+func (a AuthSettings) IsSet() bool {
+	return a.AccessKey != "" || a.KeyFile != "" || a.KeyCommand != "" || a.OAuth2.IsSet()
+}
+
+// merge replaces a with other if other configures a mechanism. Mechanisms
+// are never combined, so a profile can switch e.g. from key_file to oauth2.
+func (a *AuthSettings) merge(other AuthSettings) {
+	if other.IsSet() {
+		*a = other
+	}
+}
+
+// TLSSettings configure how the server's certificate is verified and how
+// the client authenticates itself with mutual TLS.
+type TLSSettings struct {
+	CAFile             string `mapstructure:"ca_file"`
+	CertFile           string `mapstructure:"cert_file"`
+	KeyFile            string `mapstructure:"key_file"`
+	MinVersion         string `mapstructure:"min_version"`
+	InsecureSkipVerify *bool  `mapstructure:"insecure_skip_verify"`
+}
+
+// merge overrides t with the fields set in other.
+func (t *TLSSettings) merge(other TLSSettings) {
+	if other.CAFile != "" {
+		t.CAFile = other.CAFile
+	}
+	if other.CertFile != "" {
+		t.CertFile = other.CertFile
+	}
+	if other.KeyFile != "" {
+		t.KeyFile = other.KeyFile
+	}
+	if other.MinVersion != "" {
+		t.MinVersion = other.MinVersion
+	}
+	if other.InsecureSkipVerify != nil {
+		t.InsecureSkipVerify = other.InsecureSkipVerify
+	}
+}
+
+// ProxySettings set the proxy requests go through. Unset fields fall back
+// to the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables.
+type ProxySettings struct {
+	URL     string `mapstructure:"url"`
+	NoProxy string `mapstructure:"no_proxy"`
+}
+
+// merge overrides p with the fields set in other.
+func (p *ProxySettings) merge(other ProxySettings) {
+	if other.URL != "" {
+		p.URL = other.URL
+	}
+	if other.NoProxy != "" {
+		p.NoProxy = other.NoProxy
+	}
+}
+
+// RateLimitSettings set the static request rate. The client lowers it
+// further when the server reports less remaining quota. Unset fields keep
+// the CLI's defaults.
+type RateLimitSettings struct {
+	RequestsPerSecond float64 `mapstructure:"requests_per_second"`
+	Burst             int     `mapstructure:"burst"`
+}
+
+// merge overrides r with the fields set in other.
+func (r *RateLimitSettings) merge(other RateLimitSettings) {
+	if other.RequestsPerSecond != 0 {
+		r.RequestsPerSecond = other.RequestsPerSecond
+	}
+	if other.Burst != 0 {
+		r.Burst = other.Burst
+	}
+}
+
+// RetrySettings configure how failed requests are retried. Unset fields
+// keep the client's defaults.
+type RetrySettings struct {
+	MaxRetries        *int          `mapstructure:"max_retries"`
+	WaitMin           time.Duration `mapstructure:"wait_min"`
+	WaitMax           time.Duration `mapstructure:"wait_max"`
+	Backoff           string        `mapstructure:"backoff"`
+	StatusCodes       []int         `mapstructure:"status_codes"`
+	RespectRetryAfter *bool         `mapstructure:"respect_retry_after"`
+}
+
+// merge overrides r with the fields set in other.
+func (r *RetrySettings) merge(other RetrySettings) {
+	if other.MaxRetries != nil {
+		r.MaxRetries = other.MaxRetries
+	}
+	if other.WaitMin != 0 {
+		r.WaitMin = other.WaitMin
+	}
+	if other.WaitMax != 0 {
+		r.WaitMax = other.WaitMax
+	}
+	if other.Backoff != "" {
+		r.Backoff = other.Backoff
+	}
+	if other.StatusCodes != nil {
+		r.StatusCodes = other.StatusCodes
+	}
+	if other.RespectRetryAfter != nil {
+		r.RespectRetryAfter = other.RespectRetryAfter
+	}
+}
+
+// CircuitBreakerSettings configure when requests to a failing endpoint
+// start failing fast. Unset fields keep the client's defaults.
+type CircuitBreakerSettings struct {
+	Enabled             *bool         `mapstructure:"enabled"`
+	ConsecutiveFailures *int          `mapstructure:"consecutive_failures"`
+	FailureRate         *float64      `mapstructure:"failure_rate"`
+	Window              int           `mapstructure:"window"`
+	OpenTimeout         time.Duration `mapstructure:"open_timeout"`
+}
+
+// merge overrides c with the fields set in other.
+func (c *CircuitBreakerSettings) merge(other CircuitBreakerSettings) {
+	if other.Enabled != nil {
+		c.Enabled = other.Enabled
+	}
+	if other.ConsecutiveFailures != nil {
+		c.ConsecutiveFailures = other.ConsecutiveFailures
+	}
+	if other.FailureRate != nil {
+		c.FailureRate = other.FailureRate
+	}
+	if other.Window != 0 {
+		c.Window = other.Window
+	}
+	if other.OpenTimeout != 0 {
+		c.OpenTimeout = other.OpenTimeout
+	}
+}
+
 type Config struct {
-	BaseURL string `mapstructure:"base_url"`
-	Debug   bool   `mapstructure:"debug"`
+	Settings       `mapstructure:",squash"`
+	Debug          bool                `mapstructure:"debug"`
+	DefaultProfile string              `mapstructure:"default_profile"`
+	Profiles       map[string]Settings `mapstructure:"profiles"`
+
+	// Profile is the name of the profile that was applied, if any.
+	Profile string `mapstructure:"-"`
+	// File is the config file that was read, empty if there was none.
+	File string `mapstructure:"-"`
 }
 
-func Load() (*Config, error) {
-	if err := initViperPaths(); err != nil {
+// Load reads the config file (configFile, or config.yaml in the config
+// directory when empty) and applies the named profile. An empty profile
+// falls back to ENSYNC_PROFILE and then to default_profile.
+func Load(configFile, profile string) (*Config, error) {
+	if err := initViperPaths(configFile); err != nil {
 		return nil, err
 	}
 
@@ -35,6 +246,12 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("unmarshal config: %w", err)
 	}
 
+	cfg.File = viper.ConfigFileUsed()
+
+	if err := cfg.applyProfile(profile); err != nil {
+		return nil, err
+	}
+
 	applyEnvironmentOverrides(cfg)
 
 	if err := cfg.Validate(); err != nil {
@@ -51,14 +268,52 @@ func (c *Config) Validate() error {
 	return nil
 }
 
-func initViperPaths() error {
-	viper.AddConfigPath(configDir())
-	viper.SetConfigName(configFileName)
-	viper.SetConfigType(configFileType)
+func (c *Config) applyProfile(name string) error {
+	if name == "" {
+		name = os.Getenv(envProfile)
+	}
+	if name == "" {
+		name = c.DefaultProfile
+	}
+	if name == "" {
+		return nil
+	}
+
+	profile, ok := c.Profiles[name]
+	if !ok {
+		return fmt.Errorf("profile %q not found in config file", name)
+	}
+
+	if profile.BaseURL != "" {
+		c.BaseURL = profile.BaseURL
+	}
+	if profile.Workspace != "" {
+		c.Workspace = profile.Workspace
+	}
+	c.Retry.merge(profile.Retry)
+	c.RateLimit.merge(profile.RateLimit)
+	c.CircuitBreaker.merge(profile.CircuitBreaker)
+	c.TLS.merge(profile.TLS)
+	c.Proxy.merge(profile.Proxy)
+	c.Auth.merge(profile.Auth)
+	c.Signing.merge(profile.Signing)
+	c.Profile = name
+
+	return nil
+}
+
+func initViperPaths(configFile string) error {
+	if configFile != "" {
+		viper.SetConfigFile(configFile)
+	} else {
+		viper.AddConfigPath(Dir())
+		viper.SetConfigName(configFileName)
+		viper.SetConfigType(configFileType)
+	}
 
 	if err := viper.ReadInConfig(); err != nil {
 		var notFound viper.ConfigFileNotFoundError
-		if !errors.As(err, &notFound) {
+		if configFile != "" || !errors.As(err, &notFound) {
 			return fmt.Errorf("read config file: %w", err)
 		}
 	}
@@ -71,6 +326,22 @@ func applyEnvironmentOverrides(cfg *Config) {
 		cfg.BaseURL = os.Getenv(envBaseURL)
 	}
 
+	if cfg.Workspace == "" {
+		cfg.Workspace = os.Getenv(envWorkspace)
+	}
+
+	if cfg.Auth.OAuth2.IsSet() && cfg.Auth.OAuth2.ClientSecret == "" {
+		cfg.Auth.OAuth2.ClientSecret = os.Getenv(envOAuth2ClientSecret)
+	}
+
+	if cfg.Retry.MaxRetries == nil {
+		if val := os.Getenv(envRetries); val != "" {
+			if parsed, err := strconv.Atoi(val); err == nil {
+				cfg.Retry.MaxRetries = &parsed
+			}
+		}
+	}
+
 	if !cfg.Debug {
 		if val := os.Getenv(envDebug); val != "" {
 			if parsed, err := strconv.ParseBool(val); err == nil {
@@ -80,7 +351,9 @@ func applyEnvironmentOverrides(cfg *Config) {
 	}
 }
 
-func configDir() string {
+// Dir returns the directory holding the config file and other CLI state,
+// $ENSYNC_CONFIG_DIR or ~/.ensync by default.
+func Dir() string {
 	if dir := os.Getenv(envConfigDir); dir != "" {
 		return dir
 	}
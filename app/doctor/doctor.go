@@ -0,0 +1,344 @@
+// Package doctor diagnoses the CLI's configuration and its connection to
+// the EnSync server.
+package doctor
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/EnSync-engine/CLI/app/api"
+	"github.com/EnSync-engine/CLI/app/config"
+)
+
+type Status string
+
+const (
+	StatusPass Status = "pass"
+	StatusWarn Status = "warn"
+	StatusFail Status = "fail"
+)
+
+const (
+	// SupportedServerVersion is the major version of the EnSync server API
+	// this CLI is built against.
+	SupportedServerVersion = 1
+
+	// certificateExpiryWarning is how long before its certificate expires
+	// the server's TLS check starts warning.
+	certificateExpiryWarning = 14 * 24 * time.Hour
+
+	clockSkewWarn = 30 * time.Second
+	clockSkewFail = 5 * time.Minute
+
+	// lowRateLimitHeadroom is the share of the rate limit below which the
+	// remaining quota is reported as low.
+	lowRateLimitHeadroom = 0.1
+)
+
+// Result is the outcome of one check.
+type Result struct {
+	Name   string `json:"name"`
+	Status Status `json:"status"`
+	Detail string `json:"detail"`
+	// Hint tells how to fix a warning or failure.
+	Hint string `json:"hint,omitempty"`
+}
+
+func pass(name, detail string, args ...any) Result {
+	return Result{Name: name, Status: StatusPass, Detail: fmt.Sprintf(detail, args...)}
+}
+
+func warn(name, hint, detail string, args ...any) Result {
+	return Result{Name: name, Status: StatusWarn, Detail: fmt.Sprintf(detail, args...), Hint: hint}
+}
+
+func fail(name, hint, detail string, args ...any) Result {
+	return Result{Name: name, Status: StatusFail, Detail: fmt.Sprintf(detail, args...), Hint: hint}
+}
+
+// CheckConfig reports whether the configuration loaded, given the result of
+// loading it.
+func CheckConfig(cfg *config.Config, err error) Result {
+	const name = "Configuration"
+	if err != nil {
+		return fail(name, "Fix the config file, or set ENSYNC_BASE_URL; see the README's Configuration section.", "%v", err)
+	}
+
+	source := "no config file, using environment variables"
+	if cfg.File != "" {
+		source = cfg.File
+	}
+	if cfg.Profile != "" {
+		source += fmt.Sprintf(" (profile %q)", cfg.Profile)
+	}
+	return pass(name, "%s; base URL %s", source, cfg.BaseURL)
+}
+
+// CheckReachability reports whether the server answered the probe.
+func CheckReachability(baseURL string, probe *api.ProbeResult, err error) Result {
+	const name = "Server reachable"
+	if err != nil {
+		var dnsErr *net.DNSError
+		switch {
+		case isTLSError(err):
+			return fail(name, "See the TLS check.", "TLS handshake with %s failed", baseURL)
+		case errors.As(err, &dnsErr):
+			return fail(name, "Check the host name in base_url and your DNS settings.", "cannot resolve %s: %v", dnsErr.Name, err)
+		default:
+			return fail(name, "Check base_url, that the server is running, and your network, VPN and proxy settings.", "%v", err)
+		}
+	}
+	if probe.StatusCode >= http.StatusInternalServerError {
+		return warn(name, "The server is up but failing; check its status or try again later.",
+			"%s answered %d in %s", baseURL, probe.StatusCode, probe.Latency.Round(time.Millisecond))
+	}
+	return pass(name, "%s answered %d in %s", baseURL, probe.StatusCode, probe.Latency.Round(time.Millisecond))
+}
+
+// CheckTLS reports on the security of the connection to the server.
+func CheckTLS(baseURL string, probe *api.ProbeResult, err error) Result {
+	const name = "TLS"
+	if err != nil {
+		if isTLSError(err) {
+			return fail(name, "Check that base_url names the server's certificate host, and that its CA is trusted by this machine.", "%v", err)
+		}
+		return warn(name, "", "not checked: the server could not be reached")
+	}
+
+	parsed, _ := url.Parse(baseURL)
+	if probe.TLS == nil || len(probe.TLS.PeerCertificates) == 0 {
+		if parsed != nil && isLoopback(parsed.Hostname()) {
+			return pass(name, "plain HTTP to a local server")
+		}
+		return warn(name, "Use an https:// base_url so access keys are not sent in clear text.", "connection to %s is not encrypted", baseURL)
+	}
+
+	cert := probe.TLS.PeerCertificates[0]
+	version := tls.VersionName(probe.TLS.Version)
+	if probe.InsecureSkipVerify {
+		return warn(name, "Remove tls.insecure_skip_verify and trust the server's CA with tls.ca_file instead.",
+			"%s; certificate for %s is NOT verified", version, cert.Subject.CommonName)
+	}
+	left := time.Until(cert.NotAfter)
+	if left < certificateExpiryWarning {
+		return warn(name, "Ask the server's operators to renew its certificate.",
+			"%s; certificate for %s expires %s", version, cert.Subject.CommonName, cert.NotAfter.Format(time.DateOnly))
+	}
+	return pass(name, "%s; certificate for %s valid until %s", version, cert.Subject.CommonName, cert.NotAfter.Format(time.DateOnly))
+}
+
+// CheckClockSkew compares this machine's clock with the server's Date
+// header.
+func CheckClockSkew(probe *api.ProbeResult, err error, now time.Time) Result {
+	const name = "Clock skew"
+	if err != nil {
+		return warn(name, "", "not checked: the server could not be reached")
+	}
+	if probe.ServerTime.IsZero() {
+		return warn(name, "", "not checked: the server sent no Date header")
+	}
+
+	// The Date header was written about half way through the request.
+	skew := now.Add(-probe.Latency / 2).Sub(probe.ServerTime)
+	abs := skew.Abs().Round(time.Second)
+	const hint = "Synchronise this machine's clock (e.g. enable NTP); signed requests are rejected when clocks drift."
+	switch {
+	case abs >= clockSkewFail:
+		return fail(name, hint, "local clock is %s %s the server's", abs, aheadOrBehind(skew))
+	case abs >= clockSkewWarn:
+		return warn(name, hint, "local clock is %s %s the server's", abs, aheadOrBehind(skew))
+	default:
+		return pass(name, "within %s of the server", max(abs, time.Second))
+	}
+}
+
+func aheadOrBehind(skew time.Duration) string {
+	if skew > 0 {
+		return "ahead of"
+	}
+	return "behind"
+}
+
+// CheckProxy reports which proxy, if any, requests to the server use.
+func CheckProxy(probe *api.ProbeResult, err error) Result {
+	const name = "Proxy"
+	if err != nil {
+		var opErr *net.OpError
+		if errors.As(err, &opErr) && opErr.Op == "proxyconnect" || strings.Contains(err.Error(), "resolve proxy") {
+			return fail(name, "Check HTTPS_PROXY, HTTP_PROXY and NO_PROXY.", "%v", err)
+		}
+		return warn(name, "", "not checked: the server could not be reached")
+	}
+	if probe.Proxy == nil {
+		return pass(name, "direct connection")
+	}
+	proxy := *probe.Proxy
+	proxy.User = nil
+	return pass(name, "via %s", proxy.String())
+}
+
+// CheckServerVersion reports whether the server's API version is the one
+// this CLI supports.
+func CheckServerVersion(probe *api.ProbeResult, err error, cliVersion string) Result {
+	const name = "Server version"
+	if err != nil {
+		return warn(name, "", "not checked: the server could not be reached")
+	}
+	if probe.ServerVersion == "" {
+		return warn(name, "", "the server did not report its version; CLI %s expects API v%d", cliVersion, SupportedServerVersion)
+	}
+
+	major := strings.TrimPrefix(probe.ServerVersion, "v")
+	major, _, _ = strings.Cut(major, ".")
+	if major != fmt.Sprint(SupportedServerVersion) {
+		return fail(name, "Install a CLI release matching the server's version.",
+			"server %s is not compatible with CLI %s (API v%d)", probe.ServerVersion, cliVersion, SupportedServerVersion)
+	}
+	return pass(name, "server %s, CLI %s", probe.ServerVersion, cliVersion)
+}
+
+// CheckAccessKey looks up the access key's permissions, which also proves
+// the server accepts it.
+func CheckAccessKey(ctx context.Context, client api.AccessKeyService, accessKey string) Result {
+	const name = "Access key"
+	if accessKey == "" {
+		return warn(name, "Pass --access-key, set ENSYNC_ACCESS_KEY or configure auth for the profile.", "not checked: no access key given")
+	}
+
+	key, err := client.GetAccessKeyPermissions(ctx, accessKey)
+	if err != nil {
+		var apiErr *api.APIError
+		if errors.As(err, &apiErr) {
+			switch apiErr.StatusCode {
+			case http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound:
+				return fail(name, "Check the key for typos, or ask a workspace admin for a new one.",
+					"the server rejected the key (status %d)", apiErr.StatusCode)
+			}
+		}
+		return fail(name, "See the checks above; if they pass, try again with --debug.", "%v", err)
+	}
+
+	label := api.MaskSecret(accessKey)
+	if key.Name != "" {
+		label = fmt.Sprintf("%s (%s)", key.Name, label)
+	}
+	if key.Type != "" {
+		label += ", " + key.Type
+	}
+	if key.Permissions == nil || len(key.Permissions.Send) == 0 && len(key.Permissions.Receive) == 0 {
+		return warn(name, "Grant permissions with `ensync access-key permissions set`.", "%s has no send or receive permissions", label)
+	}
+	return pass(name, "%s may send %d and receive %d event pattern(s)", label, len(key.Permissions.Send), len(key.Permissions.Receive))
+}
+
+// CheckAuthentication obtains credentials from auth and lists workspaces
+// with them, which proves the server accepts them. A nil auth checks
+// requests identified by their signing key pair alone.
+func CheckAuthentication(ctx context.Context, client api.WorkspaceService, auth api.Authenticator) Result {
+	const name = "Authentication"
+	var credentials any = auth
+	if auth == nil {
+		credentials = "the signing key pair"
+	}
+
+	params := api.DefaultListParams()
+	params.Limit = 1
+	_, err := client.ListWorkspaces(ctx, params)
+	if err != nil {
+		var apiErr *api.APIError
+		if errors.As(err, &apiErr) {
+			switch apiErr.StatusCode {
+			case http.StatusUnauthorized:
+				return fail(name, "Check the profile's auth settings, or ask a workspace admin for new credentials.",
+					"the server rejected the credentials from %s", credentials)
+			case http.StatusForbidden:
+				return pass(name, "%s accepted by the server (it may not list workspaces)", credentials)
+			}
+		}
+		return fail(name, "Check the profile's auth settings; try again with --debug.", "%v", err)
+	}
+	return pass(name, "%s accepted by the server", credentials)
+}
+
+// CheckRateLimit reports how much of the server's rate limit is left.
+func CheckRateLimit(quota api.RateLimitQuota, ok bool) Result {
+	const name = "Rate limit"
+	if !ok {
+		return pass(name, "the server reported no rate limit")
+	}
+
+	reset := max(time.Until(quota.Reset), 0).Round(time.Second)
+	detail := fmt.Sprintf("%d request(s) left, resets in %s", quota.Remaining, reset)
+	if quota.Limit > 0 {
+		detail = fmt.Sprintf("%d of %d request(s) left, resets in %s", quota.Remaining, quota.Limit, reset)
+	}
+
+	const hint = "Other clients share this key's quota; lower rate_limit.requests_per_second or wait for the reset."
+	switch {
+	case quota.Remaining <= 0:
+		return fail(name, hint, "%s", detail)
+	case quota.Limit > 0 && float64(quota.Remaining) < float64(quota.Limit)*lowRateLimitHeadroom:
+		return warn(name, hint, "%s", detail)
+	default:
+		return pass(name, "%s", detail)
+	}
+}
+
+// CheckCircuits reports the circuit breaker state of the endpoints
+// requested so far.
+func CheckCircuits(states []api.CircuitStatus, enabled bool) Result {
+	const name = "Circuit breaker"
+	if !enabled {
+		return pass(name, "disabled")
+	}
+
+	var open []string
+	for _, state := range states {
+		if state.State != api.CircuitClosed {
+			open = append(open, fmt.Sprintf("%s %s", state.Endpoint, state.State))
+		}
+	}
+	if len(open) > 0 {
+		return warn(name, "The server is failing repeatedly; requests to these endpoints fail fast until it recovers.", "%s", strings.Join(open, ", "))
+	}
+	return pass(name, "all circuits closed")
+}
+
+func isTLSError(err error) bool {
+	var (
+		unknownAuthority x509.UnknownAuthorityError
+		hostname         x509.HostnameError
+		invalid          x509.CertificateInvalidError
+		verification     *tls.CertificateVerificationError
+		record           tls.RecordHeaderError
+	)
+	return errors.As(err, &unknownAuthority) || errors.As(err, &hostname) || errors.As(err, &invalid) ||
+		errors.As(err, &verification) || errors.As(err, &record)
+}
+
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// Failed returns how many results failed.
+func Failed(results []Result) int {
+	failed := 0
+	for _, result := range results {
+		if result.Status == StatusFail {
+			failed++
+		}
+	}
+	return failed
+}
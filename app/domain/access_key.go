@@ -1,9 +1,19 @@
 package domain
 
-import "time"
+import (
+	"slices"
+	"strings"
+	"time"
+)
 
 const (
 	permissionWildcard = "*"
+
+	AccessKeyStateActive   = "ACTIVE"
+	AccessKeyStateDisabled = "DISABLED"
+	// AccessKeyStateExpired is reported for keys past their expiry; it
+	// cannot be set.
+	AccessKeyStateExpired = "EXPIRED"
 )
 
 type AccessKey struct {
@@ -12,6 +22,7 @@ type AccessKey struct {
 	Name           string          `json:"name,omitempty"`
 	Type           string          `json:"type,omitempty"`
 	CreatedAt      time.Time       `json:"createdAt,omitempty"`
+	ExpiresAt      *time.Time      `json:"expiresAt,omitempty"`
 	Permissions    *Permissions    `json:"permissions,omitempty"`
 	ServiceKeyID   string          `json:"service_key_id,omitempty"`
 	ServiceKeyPair *ServiceKeyPair `json:"service_key_pair,omitempty"`
@@ -29,14 +40,20 @@ type Permissions struct {
 }
 
 type AccessKeyPermissions struct {
-	ID             string          `json:"id,omitempty"`
-	Key            string          `json:"key"`
-	Name           string          `json:"name,omitempty"`
-	Type           string          `json:"type,omitempty"`
-	CreatedAt      time.Time       `json:"createdAt,omitempty"`
-	Permissions    *Permissions    `json:"permissions"`
-	ServiceKeyID   string          `json:"service_key_id,omitempty"`
-	ServiceKeyPair *ServiceKeyPair `json:"service_key_pair,omitempty"`
+	ID             string            `json:"id,omitempty"`
+	Key            string            `json:"key"`
+	Name           string            `json:"name,omitempty"`
+	Description    string            `json:"description,omitempty"`
+	Labels         map[string]string `json:"labels,omitempty"`
+	State          string            `json:"state,omitempty"`
+	Type           string            `json:"type,omitempty"`
+	Workspace      string            `json:"workspace,omitempty"`
+	CreatedAt      time.Time         `json:"createdAt,omitempty"`
+	ExpiresAt      *time.Time        `json:"expiresAt,omitempty"`
+	RotatedAt      *time.Time        `json:"rotatedAt,omitempty"`
+	Permissions    *Permissions      `json:"permissions"`
+	ServiceKeyID   string            `json:"service_key_id,omitempty"`
+	ServiceKeyPair *ServiceKeyPair   `json:"service_key_pair,omitempty"`
 }
 
 type AccessKeyList struct {
@@ -48,12 +65,65 @@ type CreateAccessKeyRequest struct {
 	Type        string       `json:"type"`
 	Name        string       `json:"name"`
 	Permissions *Permissions `json:"permissions"`
+	// ExpiresAt is when the key stops working; nil keys never expire.
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+// UpdateAccessKeyRequest is a JSON Merge Patch document for an access key.
+// Nil fields are left untouched; labels set to nil are removed.
+type UpdateAccessKeyRequest struct {
+	Name        *string            `json:"name,omitempty"`
+	Description *string            `json:"description,omitempty"`
+	Labels      map[string]*string `json:"labels,omitempty"`
+	State       *string            `json:"state,omitempty"`
+}
+
+func (r *UpdateAccessKeyRequest) IsEmpty() bool {
+	return r.Name == nil && r.Description == nil && r.Labels == nil && r.State == nil
 }
 
 type UpdateServiceKeyPairRequest struct {
 	AccessKey string `json:"access_key"`
 }
 
+// ExpiresBefore reports whether the key expires before t. Keys without an
+// expiry never do.
+func (k *AccessKeyPermissions) ExpiresBefore(t time.Time) bool {
+	return k.ExpiresAt != nil && k.ExpiresAt.Before(t)
+}
+
+// EffectiveState returns the key's state at now: EXPIRED once it is past
+// its expiry, else its state, ACTIVE for keys without one.
+func (k *AccessKeyPermissions) EffectiveState(now time.Time) string {
+	switch {
+	case k.ExpiresAt != nil && !k.ExpiresAt.After(now):
+		return AccessKeyStateExpired
+	case k.State == "":
+		return AccessKeyStateActive
+	default:
+		return strings.ToUpper(k.State)
+	}
+}
+
+// HasLabels reports whether the key has every label in labels.
+func (k *AccessKeyPermissions) HasLabels(labels map[string]string) bool {
+	for name, value := range labels {
+		if got, ok := k.Labels[name]; !ok || got != value {
+			return false
+		}
+	}
+	return true
+}
+
+// KeyPairAge returns how long ago the service key pair was issued: when it
+// was last rotated, or else when the key was created.
+func (k *AccessKeyPermissions) KeyPairAge(now time.Time) time.Duration {
+	if k.RotatedAt != nil {
+		return now.Sub(*k.RotatedAt)
+	}
+	return now.Sub(k.CreatedAt)
+}
+
 func (p *Permissions) HasSendPermission(channel string) bool {
 	return containsOrWildcard(p.Send, channel)
 }
@@ -62,6 +132,55 @@ func (p *Permissions) HasReceivePermission(channel string) bool {
 	return containsOrWildcard(p.Receive, channel)
 }
 
+// References reports whether the event name is listed explicitly (not via
+// wildcard) in the send or receive permissions.
+func (p *Permissions) References(eventName string) bool {
+	return slices.Contains(p.Send, eventName) || slices.Contains(p.Receive, eventName)
+}
+
+// RenameEvent returns a copy of the permissions with every explicit
+// reference to oldName replaced by newName.
+func (p *Permissions) RenameEvent(oldName, newName string) *Permissions {
+	renamed := &Permissions{
+		Send:      replaceAll(p.Send, oldName, newName),
+		Receive:   replaceAll(p.Receive, oldName, newName),
+		Resources: p.Resources,
+	}
+	return renamed
+}
+
+// AddEventAlias returns a copy of the permissions that also lists alias
+// wherever name is listed explicitly, so a key keeps its access while the
+// event is renamed from name to alias.
+func (p *Permissions) AddEventAlias(name, alias string) *Permissions {
+	return &Permissions{
+		Send:      addAlias(p.Send, name, alias),
+		Receive:   addAlias(p.Receive, name, alias),
+		Resources: p.Resources,
+	}
+}
+
+func addAlias(slice []string, value, alias string) []string {
+	if !slices.Contains(slice, value) || slices.Contains(slice, alias) {
+		return slices.Clone(slice)
+	}
+	return append(slices.Clone(slice), alias)
+}
+
+func replaceAll(slice []string, oldValue, newValue string) []string {
+	if slice == nil {
+		return nil
+	}
+	out := make([]string, len(slice))
+	for i, v := range slice {
+		if v == oldValue {
+			v = newValue
+		}
+		out[i] = v
+	}
+	return out
+}
+
 func containsOrWildcard(slice []string, value string) bool {
 	for _, v := range slice {
 		if v == permissionWildcard || v == value {
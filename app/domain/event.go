@@ -1,11 +1,17 @@
 package domain
 
-import "time"
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
 
 type Event struct {
 	ID        string         `json:"id,omitempty"`
 	Name      string         `json:"name"`
 	Payload   map[string]any `json:"payload,omitempty"`
+	Workspace string         `json:"workspace,omitempty"`
 	CreatedAt time.Time      `json:"createdAt,omitempty"`
 	UpdatedAt time.Time      `json:"updatedAt,omitempty"`
 }
@@ -15,6 +21,95 @@ type EventList struct {
 	Results       []*Event `json:"results"`
 }
 
+// EventPatch is a JSON Merge Patch document for an event. Nil fields are
+// omitted and left untouched; payload keys set to nil are removed. An empty,
+// non-nil payload is sent as {}.
+type EventPatch struct {
+	Name    *string        `json:"name,omitempty"`
+	Payload map[string]any `json:"payload"`
+}
+
+func (p *EventPatch) IsEmpty() bool {
+	return p.Name == nil && p.Payload == nil
+}
+
+// MarshalJSON leaves out a nil payload but keeps an empty one, which
+// omitempty would drop too.
+func (p EventPatch) MarshalJSON() ([]byte, error) {
+	doc := make(map[string]any, 2)
+	if p.Name != nil {
+		doc["name"] = *p.Name
+	}
+	if p.Payload != nil {
+		doc["payload"] = p.Payload
+	}
+	return json.Marshal(doc)
+}
+
+// NewEventPatch returns the merge patch that turns before into after.
+func NewEventPatch(before, after *Event) *EventPatch {
+	patch := &EventPatch{}
+	if before.Name != after.Name {
+		name := after.Name
+		patch.Name = &name
+	}
+	if diff := diffObjects(before.Payload, after.Payload); len(diff) > 0 {
+		patch.Payload = diff
+	}
+	return patch
+}
+
+// diffObjects returns the RFC 7396 merge patch between two JSON objects:
+// removed keys map to nil, nested objects are diffed recursively and any
+// other changed value is replaced wholesale.
+func diffObjects(before, after map[string]any) map[string]any {
+	diff := make(map[string]any)
+
+	for key := range before {
+		if _, ok := after[key]; !ok {
+			diff[key] = nil
+		}
+	}
+
+	for key, newValue := range after {
+		oldValue, ok := before[key]
+		if !ok {
+			diff[key] = newValue
+			continue
+		}
+
+		oldObject, oldIsObject := oldValue.(map[string]any)
+		newObject, newIsObject := newValue.(map[string]any)
+		if oldIsObject && newIsObject {
+			if nested := diffObjects(oldObject, newObject); len(nested) > 0 {
+				diff[key] = nested
+			}
+			continue
+		}
+
+		if !reflect.DeepEqual(oldValue, newValue) {
+			diff[key] = newValue
+		}
+	}
+
+	return diff
+}
+
 func (e *Event) IsZero() bool {
 	return e.ID == "" && e.Name == ""
 }
+
+// WorkspacePath returns the workspace the event belongs to. When the
+// server does not say, it is the prefix of a path-style name, e.g.
+// "gms/urbanhero" for "gms/urbanhero/stripe"; names without a slash live
+// at the top level and return an empty path.
+func (e *Event) WorkspacePath() string {
+	if e.Workspace != "" {
+		return e.Workspace
+	}
+	idx := strings.LastIndex(e.Name, "/")
+	if idx < 0 {
+		return ""
+	}
+	return e.Name[:idx]
+}
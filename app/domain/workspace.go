@@ -1,6 +1,9 @@
 package domain
 
-import "time"
+import (
+	"sort"
+	"time"
+)
 
 type Workspace struct {
 	ID        string       `json:"id"`
@@ -17,5 +20,65 @@ type WorkspaceList struct {
 }
 
 type CreateWorkspaceRequest struct {
+	Name     string `json:"name"`
+	ParentID string `json:"parentId,omitempty"`
+}
+
+type UpdateWorkspaceRequest struct {
 	Name string `json:"name"`
 }
+
+type MoveWorkspaceRequest struct {
+	ParentID string `json:"parentId"`
+}
+
+// BuildWorkspaceTree returns the root workspaces with Children populated.
+// Results that already arrive nested are returned as-is; flat results are
+// linked through ParentID. Workspaces whose parent is missing become roots.
+func BuildWorkspaceTree(workspaces []*Workspace) []*Workspace {
+	for _, ws := range workspaces {
+		if len(ws.Children) > 0 {
+			return workspaces
+		}
+	}
+
+	byID := make(map[string]*Workspace, len(workspaces))
+	for _, ws := range workspaces {
+		byID[ws.ID] = ws
+	}
+
+	var roots []*Workspace
+	for _, ws := range workspaces {
+		parent, ok := byID[ws.ParentID]
+		if ws.ParentID == "" || !ok || parent == ws {
+			roots = append(roots, ws)
+			continue
+		}
+		parent.Children = append(parent.Children, ws)
+	}
+
+	sortWorkspaces(roots)
+	return roots
+}
+
+// FindByPath searches the tree depth-first for the workspace with the given path.
+func FindByPath(workspaces []*Workspace, path string) *Workspace {
+	for _, ws := range workspaces {
+		if ws.Path == path {
+			return ws
+		}
+		if found := FindByPath(ws.Children, path); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func sortWorkspaces(workspaces []*Workspace) {
+	sort.Slice(workspaces, func(i, j int) bool {
+		return workspaces[i].Name < workspaces[j].Name
+	})
+	for _, ws := range workspaces {
+		sortWorkspaces(ws.Children)
+	}
+}
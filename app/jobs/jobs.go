@@ -0,0 +1,302 @@
+// Package jobs persists batch jobs so they can be inspected and resumed.
+//
+// Each job lives in its own directory holding the job metadata, a copy of
+// its input records and an append-only journal with one result per
+// processed record. A job is resumed by replaying the records whose latest
+// journal entry is not a success.
+package jobs
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/EnSync-engine/CLI/app/bulk"
+)
+
+const (
+	jobFile     = "job.json"
+	recordsFile = "records.ndjson"
+	journalFile = "journal.ndjson"
+)
+
+// ErrNotFound is returned when no job has the requested ID.
+var ErrNotFound = errors.New("job not found")
+
+type Status string
+
+const (
+	StatusRunning     Status = "running"
+	StatusCompleted   Status = "completed"
+	StatusFailed      Status = "failed"
+	StatusInterrupted Status = "interrupted"
+	StatusCancelled   Status = "cancelled"
+)
+
+// Job describes a batch job. Params holds kind-specific options, such as
+// the default access key type of a bulk access key import. Access keys are
+// never stored; resuming a job requires passing one again.
+type Job struct {
+	ID              string            `json:"id"`
+	Kind            string            `json:"kind"`
+	Status          Status            `json:"status"`
+	Source          string            `json:"source"`
+	ResultsFile     string            `json:"resultsFile"`
+	BaseURL         string            `json:"baseUrl"`
+	Workspace       string            `json:"workspace,omitempty"`
+	Workers         int               `json:"workers"`
+	ContinueOnError bool              `json:"continueOnError"`
+	Params          map[string]string `json:"params,omitempty"`
+	Total           int               `json:"total"`
+	CreatedAt       time.Time         `json:"createdAt"`
+	UpdatedAt       time.Time         `json:"updatedAt"`
+}
+
+// Resumable reports whether the job can be continued.
+func (j *Job) Resumable() bool {
+	return j.Status != StatusCompleted && j.Status != StatusCancelled
+}
+
+// Store keeps jobs in subdirectories of dir.
+type Store struct {
+	dir string
+}
+
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// Create assigns the job an ID and stores it with its records.
+func (s *Store) Create(job *Job, records []bulk.Record) error {
+	id, err := newID()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	job.ID = id
+	job.Status = StatusRunning
+	job.Total = len(records)
+	job.CreatedAt = now
+	job.UpdatedAt = now
+
+	if err := os.MkdirAll(s.jobDir(id), 0o700); err != nil {
+		return fmt.Errorf("create job directory: %w", err)
+	}
+	if err := writeLines(filepath.Join(s.jobDir(id), recordsFile), records); err != nil {
+		return fmt.Errorf("store job records: %w", err)
+	}
+	return s.Save(job)
+}
+
+// Save writes the job metadata, replacing the previous version atomically.
+func (s *Store) Save(job *Job) error {
+	job.UpdatedAt = time.Now().UTC()
+
+	data, err := json.MarshalIndent(job, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode job: %w", err)
+	}
+
+	path := filepath.Join(s.jobDir(job.ID), jobFile)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("save job %s: %w", job.ID, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("save job %s: %w", job.ID, err)
+	}
+	return nil
+}
+
+func (s *Store) Get(id string) (*Job, error) {
+	data, err := os.ReadFile(filepath.Join(s.jobDir(id), jobFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read job %s: %w", id, err)
+	}
+
+	var job Job
+	if err := json.Unmarshal(data, &job); err != nil {
+		return nil, fmt.Errorf("decode job %s: %w", id, err)
+	}
+	return &job, nil
+}
+
+// List returns all jobs, most recent first.
+func (s *Store) List() ([]*Job, error) {
+	entries, err := os.ReadDir(s.dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("list jobs: %w", err)
+	}
+
+	var list []*Job
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		job, err := s.Get(entry.Name())
+		if err != nil {
+			continue
+		}
+		list = append(list, job)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].CreatedAt.After(list[j].CreatedAt)
+	})
+	return list, nil
+}
+
+// Cancel marks the job as cancelled. A process still running the job
+// notices and stops; cancelled jobs cannot be resumed.
+func (s *Store) Cancel(id string) (*Job, error) {
+	job, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status == StatusCompleted {
+		return nil, fmt.Errorf("job %s has already completed", id)
+	}
+
+	job.Status = StatusCancelled
+	if err := s.Save(job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// Records returns the job's input records.
+func (s *Store) Records(job *Job) ([]bulk.Record, error) {
+	records, err := readLines[bulk.Record](filepath.Join(s.jobDir(job.ID), recordsFile))
+	if err != nil {
+		return nil, fmt.Errorf("read job records: %w", err)
+	}
+	return records, nil
+}
+
+// Results returns the latest journaled result for each record, in input
+// order, without outputs. Records that were never processed are reported
+// as skipped.
+func (s *Store) Results(job *Job) ([]bulk.Result, error) {
+	records, err := s.Records(job)
+	if err != nil {
+		return nil, err
+	}
+
+	journal, err := readLines[bulk.Result](filepath.Join(s.jobDir(job.ID), journalFile))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("read job journal: %w", err)
+	}
+	latest := make(map[int]bulk.Result, len(journal))
+	for _, result := range journal {
+		latest[result.Line] = result
+	}
+
+	results := make([]bulk.Result, len(records))
+	for i, record := range records {
+		result, ok := latest[record.Line]
+		if !ok {
+			result = bulk.Result{Line: record.Line, Status: bulk.StatusSkipped}
+		}
+		results[i] = result
+	}
+	return results, nil
+}
+
+// OpenJournal opens the job's journal for appending results.
+func (s *Store) OpenJournal(job *Job) (*Journal, error) {
+	file, err := os.OpenFile(filepath.Join(s.jobDir(job.ID), journalFile), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("open job journal: %w", err)
+	}
+	return &Journal{file: file}, nil
+}
+
+func (s *Store) jobDir(id string) string {
+	return filepath.Join(s.dir, filepath.Base(id))
+}
+
+// Journal is the append-only checkpoint log of a job.
+type Journal struct {
+	file *os.File
+}
+
+// Append records a result and syncs it to disk, so that it survives the
+// process dying right after. The result's output is not recorded: it may
+// hold secrets, such as the key pair of a created access key.
+func (j *Journal) Append(result bulk.Result) error {
+	result.Output = nil
+	data, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	if _, err := j.file.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	return j.file.Sync()
+}
+
+func (j *Journal) Close() error {
+	return j.file.Close()
+}
+
+// newID returns a sortable, unique job ID such as 20240102-150405-1a2b3c.
+func newID() (string, error) {
+	suffix := make([]byte, 3)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", fmt.Errorf("generate job ID: %w", err)
+	}
+	return time.Now().UTC().Format("20060102-150405") + "-" + hex.EncodeToString(suffix), nil
+}
+
+func writeLines[T any](path string, values []T) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+
+	encoder := json.NewEncoder(file)
+	for _, value := range values {
+		if err := encoder.Encode(value); err != nil {
+			return err
+		}
+	}
+	return file.Sync()
+}
+
+// readLines decodes one JSON value per line. A truncated last line, left
+// by a process dying mid-write, is ignored.
+func readLines[T any](path string) ([]T, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+
+	var values []T
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var value T
+		if err := json.Unmarshal(scanner.Bytes(), &value); err != nil {
+			continue
+		}
+		values = append(values, value)
+	}
+	return values, scanner.Err()
+}
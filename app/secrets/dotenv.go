@@ -0,0 +1,44 @@
+package secrets
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// NewDotenvSink writes secrets to path as a .env file. Each secret's
+// variables are named ENSYNC_<NAME>_ACCESS_KEY, ENSYNC_<NAME>_PUBLIC_KEY and
+// ENSYNC_<NAME>_PRIVATE_KEY after the key's name.
+func NewDotenvSink(path string) (Sink, error) {
+	return newFileSink("dotenv file", path, encodeDotenv)
+}
+
+func encodeDotenv(secrets []Secret) ([]byte, error) {
+	names := newNamer(envName)
+	var b strings.Builder
+	for i, secret := range secrets {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		prefix := "ENSYNC"
+		if name := names.name(secret); name != "" {
+			prefix += "_" + name
+		}
+		fmt.Fprintf(&b, "# Access key %q", secret.Name)
+		if secret.ID != "" {
+			fmt.Fprintf(&b, " (%s)", secret.ID)
+		}
+		fmt.Fprintf(&b, ", issued %s\n", secret.IssuedAt.Format(time.RFC3339))
+		for _, value := range secret.values("ACCESS_KEY", "PUBLIC_KEY", "PRIVATE_KEY") {
+			fmt.Fprintf(&b, "%s_%s=%q\n", prefix, value[0], value[1])
+		}
+	}
+	return []byte(b.String()), nil
+}
+
+// envName turns s into an upper case environment variable name part.
+func envName(s string) string {
+	return replaceInvalid(strings.ToUpper(s), func(r rune) bool {
+		return r >= 'A' && r <= 'Z' || r >= '0' && r <= '9'
+	}, '_')
+}
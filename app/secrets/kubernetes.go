@@ -0,0 +1,76 @@
+package secrets
+
+import (
+	"bytes"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const maxKubernetesName = 253
+
+type kubernetesSecret struct {
+	APIVersion string             `yaml:"apiVersion"`
+	Kind       string             `yaml:"kind"`
+	Metadata   kubernetesMetadata `yaml:"metadata"`
+	Type       string             `yaml:"type"`
+	StringData map[string]string  `yaml:"stringData"`
+}
+
+type kubernetesMetadata struct {
+	Name        string            `yaml:"name"`
+	Labels      map[string]string `yaml:"labels"`
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+}
+
+// NewKubernetesSink writes secrets to path as Kubernetes Secret manifests,
+// one named ensync-<name> per key, ready for kubectl apply. The data keys
+// are ENSYNC_ACCESS_KEY, ENSYNC_PUBLIC_KEY and ENSYNC_PRIVATE_KEY, so a
+// pod can load a secret with envFrom.
+func NewKubernetesSink(path string) (Sink, error) {
+	return newFileSink("Kubernetes manifest", path, encodeKubernetes)
+}
+
+func encodeKubernetes(secrets []Secret) ([]byte, error) {
+	names := newNamer(kubernetesName)
+	var b bytes.Buffer
+	encoder := yaml.NewEncoder(&b)
+	encoder.SetIndent(2)
+	for _, secret := range secrets {
+		manifest := kubernetesSecret{
+			APIVersion: "v1",
+			Kind:       "Secret",
+			Metadata: kubernetesMetadata{
+				Name:   "ensync-" + names.name(secret),
+				Labels: map[string]string{"app.kubernetes.io/managed-by": "ensync-cli"},
+			},
+			Type:       "Opaque",
+			StringData: make(map[string]string),
+		}
+		if secret.ID != "" {
+			manifest.Metadata.Annotations = map[string]string{"ensync.io/access-key-id": secret.ID}
+		}
+		for _, value := range secret.values("ENSYNC_ACCESS_KEY", "ENSYNC_PUBLIC_KEY", "ENSYNC_PRIVATE_KEY") {
+			manifest.StringData[value[0]] = value[1]
+		}
+		if err := encoder.Encode(manifest); err != nil {
+			return nil, err
+		}
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// kubernetesName turns s into a DNS subdomain name part, as Kubernetes
+// requires of object names.
+func kubernetesName(s string) string {
+	name := replaceInvalid(strings.ToLower(s), func(r rune) bool {
+		return r >= 'a' && r <= 'z' || r >= '0' && r <= '9'
+	}, '-')
+	if limit := maxKubernetesName - len("ensync-"); len(name) > limit {
+		name = strings.TrimRight(name[:limit], "-")
+	}
+	return name
+}
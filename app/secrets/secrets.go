@@ -0,0 +1,168 @@
+// Package secrets stores access key credentials issued by the API, such as
+// new service key pairs, somewhere other than the terminal: a private file,
+// an encrypted bundle, a .env file, a Kubernetes Secret manifest or a Vault
+// KV store.
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/EnSync-engine/CLI/app/bundle"
+	"github.com/EnSync-engine/CLI/app/domain"
+)
+
+// Secret is an access key together with the service key pair issued for it.
+type Secret struct {
+	ID             string                 `json:"id,omitempty"`
+	Name           string                 `json:"name,omitempty"`
+	AccessKey      string                 `json:"accessKey"`
+	ServiceKeyPair *domain.ServiceKeyPair `json:"service_key_pair,omitempty"`
+	IssuedAt       time.Time              `json:"issuedAt"`
+}
+
+// values returns the secret's fields under the given names, in a stable
+// order, leaving out the empty ones.
+func (s Secret) values(accessKey, publicKey, privateKey string) [][2]string {
+	values := [][2]string{{accessKey, s.AccessKey}}
+	if s.ServiceKeyPair != nil {
+		values = append(values, [2]string{publicKey, s.ServiceKeyPair.PublicKey})
+		if s.ServiceKeyPair.PrivateKey != "" {
+			values = append(values, [2]string{privateKey, s.ServiceKeyPair.PrivateKey})
+		}
+	}
+	return values
+}
+
+// Sink stores secrets. Secrets are put one at a time as they are issued, so
+// the ones put before a failure are kept.
+type Sink interface {
+	Put(ctx context.Context, secret Secret) error
+	// String describes where the secrets go, for messages.
+	String() string
+}
+
+// fileSink rewrites its file with every secret put so far on each Put. The
+// file is only readable by the user and is replaced atomically, so an
+// interrupted write keeps the secrets written before.
+type fileSink struct {
+	kind    string
+	path    string
+	encode  func([]Secret) ([]byte, error)
+	secrets []Secret
+}
+
+// newFileSink refuses to use an existing file, which may hold the only copy
+// of earlier secrets.
+func newFileSink(kind, path string, encode func([]Secret) ([]byte, error)) (*fileSink, error) {
+	if path == "" {
+		return nil, fmt.Errorf("%s path is empty", kind)
+	}
+	if _, err := os.Stat(path); err == nil {
+		return nil, fmt.Errorf("%s %q already exists", kind, path)
+	}
+	return &fileSink{kind: kind, path: path, encode: encode}, nil
+}
+
+func (s *fileSink) Put(_ context.Context, secret Secret) error {
+	data, err := s.encode(append(s.secrets, secret))
+	if err != nil {
+		return fmt.Errorf("encode %s: %w", s.kind, err)
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("write %s %q: %w", s.kind, s.path, err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("write %s %q: %w", s.kind, s.path, err)
+	}
+	s.secrets = append(s.secrets, secret)
+	return nil
+}
+
+func (s *fileSink) String() string {
+	return s.kind + " " + s.path
+}
+
+// NewFileSink writes secrets to path as a JSON array.
+func NewFileSink(path string) (Sink, error) {
+	return newFileSink("file", path, encodeJSON)
+}
+
+// NewBundleSink writes secrets to path as a JSON array sealed in a bundle
+// with passphrase. Read it back with bundle.ReadFile.
+func NewBundleSink(path, passphrase string) (Sink, error) {
+	sink, err := newFileSink("bundle", path, nil)
+	if err != nil {
+		return nil, err
+	}
+	// The key is derived once, after the path is known to be usable, rather
+	// than on every Put.
+	sealer, err := bundle.NewSealer(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	sink.encode = func(secrets []Secret) ([]byte, error) {
+		plaintext, err := encodeJSON(secrets)
+		if err != nil {
+			return nil, err
+		}
+		return sealer.Seal(plaintext)
+	}
+	return sink, nil
+}
+
+func encodeJSON(secrets []Secret) ([]byte, error) {
+	data, err := json.MarshalIndent(secrets, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// namer gives each secret a unique name derived from its key's name, or
+// its ID for unnamed keys. Keys sharing a name get their ID appended.
+type namer struct {
+	sanitize func(string) string
+	seen     map[string]bool
+}
+
+func newNamer(sanitize func(string) string) *namer {
+	return &namer{sanitize: sanitize, seen: make(map[string]bool)}
+}
+
+func (n *namer) name(secret Secret) string {
+	name := n.sanitize(secret.Name)
+	if name == "" {
+		name = n.sanitize(secret.ID)
+	}
+	if n.seen[name] && secret.ID != "" {
+		name = n.sanitize(secret.Name + "-" + secret.ID)
+	}
+	n.seen[name] = true
+	return name
+}
+
+// replaceInvalid replaces each run of runes not accepted by valid with sep,
+// trimming sep from both ends.
+func replaceInvalid(s string, valid func(rune) bool, sep rune) string {
+	var b strings.Builder
+	pending := false
+	for _, r := range s {
+		if !valid(r) {
+			pending = b.Len() > 0
+			continue
+		}
+		if pending {
+			b.WriteRune(sep)
+			pending = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
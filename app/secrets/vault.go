@@ -0,0 +1,121 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const vaultTimeout = 30 * time.Second
+
+// VaultOptions configures a sink writing to a HashiCorp Vault KV version 2
+// secrets engine, or a server compatible with its API.
+type VaultOptions struct {
+	// Address is the Vault server URL, e.g. https://vault.example:8200.
+	Address string
+	Token   string
+	// Mount is where the KV engine is mounted, e.g. "secret".
+	Mount string
+	// Path is the directory under the mount the secrets are written to.
+	Path string
+	// HTTPClient defaults to a client with a 30 second timeout.
+	HTTPClient *http.Client
+}
+
+// vaultSink writes each secret to <mount>/data/<path>/<name>, creating a new
+// version if it exists. The values are named access_key, public_key and
+// private_key.
+type vaultSink struct {
+	opts  VaultOptions
+	base  *url.URL
+	names *namer
+}
+
+// NewVaultSink returns a sink writing to the KV store described by opts.
+func NewVaultSink(opts VaultOptions) (Sink, error) {
+	base, err := url.Parse(opts.Address)
+	if err != nil {
+		return nil, fmt.Errorf("parse Vault address: %w", err)
+	}
+	if base.Scheme != "http" && base.Scheme != "https" {
+		return nil, fmt.Errorf("Vault address %q must be an http or https URL", opts.Address)
+	}
+	if opts.Token == "" {
+		return nil, errors.New("Vault token is required")
+	}
+	opts.Mount = strings.Trim(opts.Mount, "/")
+	opts.Path = strings.Trim(opts.Path, "/")
+	if opts.Mount == "" {
+		return nil, errors.New("Vault mount is required")
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = &http.Client{Timeout: vaultTimeout}
+	}
+	return &vaultSink{opts: opts, base: base, names: newNamer(vaultName)}, nil
+}
+
+func (s *vaultSink) String() string {
+	return fmt.Sprintf("Vault %s at %s", strings.Trim(s.opts.Mount+"/"+s.opts.Path, "/"), s.opts.Address)
+}
+
+type vaultWrite struct {
+	Data map[string]string `json:"data"`
+}
+
+type vaultErrors struct {
+	Errors []string `json:"errors"`
+}
+
+func (s *vaultSink) Put(ctx context.Context, secret Secret) error {
+	name := s.names.name(secret)
+	if name == "" {
+		return errors.New("secret has neither a name nor an ID")
+	}
+	write := vaultWrite{Data: make(map[string]string)}
+	for _, value := range secret.values("access_key", "public_key", "private_key") {
+		write.Data[value[0]] = value[1]
+	}
+	body, err := json.Marshal(write)
+	if err != nil {
+		return fmt.Errorf("encode Vault secret: %w", err)
+	}
+
+	path := strings.Trim(s.opts.Path+"/"+name, "/")
+	endpoint := s.base.JoinPath("v1", s.opts.Mount, "data", path)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.String(), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create Vault request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Vault-Token", s.opts.Token)
+
+	resp, err := s.opts.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("write Vault secret %q: %w", path, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	var vaultErr vaultErrors
+	if json.Unmarshal(data, &vaultErr) == nil && len(vaultErr.Errors) > 0 {
+		return fmt.Errorf("write Vault secret %q: status %d: %s", path, resp.StatusCode, strings.Join(vaultErr.Errors, "; "))
+	}
+	return fmt.Errorf("write Vault secret %q: status %d", path, resp.StatusCode)
+}
+
+// vaultName turns s into a single Vault path segment.
+func vaultName(s string) string {
+	return replaceInvalid(s, func(r rune) bool {
+		return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.'
+	}, '-')
+}
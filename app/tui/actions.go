@@ -0,0 +1,157 @@
+package tui
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/EnSync-engine/CLI/app/domain"
+	"github.com/EnSync-engine/CLI/pkg/editor"
+)
+
+// pendingAction is a destructive action waiting for the user to confirm it.
+type pendingAction struct {
+	question string
+	progress string
+	run      tea.Cmd
+}
+
+func (m *model) ask(action *pendingAction) {
+	m.confirm = action
+	m.mode = modeConfirm
+}
+
+func (m *model) askDelete() {
+	selected, ok := m.current().selected(m.query())
+	if !ok {
+		return
+	}
+
+	var remove func() error
+	switch value := selected.value.(type) {
+	case *domain.Event:
+		remove = func() error { return m.client.DeleteEvent(m.ctx, value.ID) }
+	case *domain.AccessKeyPermissions:
+		remove = func() error { return m.client.DeleteAccessKey(m.ctx, value.ID) }
+	case *domain.Workspace:
+		remove = func() error { return m.client.DeleteWorkspace(m.ctx, value.ID, false) }
+	default:
+		return
+	}
+
+	m.ask(&pendingAction{
+		question: fmt.Sprintf("Delete %q?", selected.title),
+		progress: fmt.Sprintf("Deleting %q...", selected.title),
+		run: func() tea.Msg {
+			if err := remove(); err != nil {
+				return actionDoneMsg{err: err}
+			}
+			return actionDoneMsg{status: fmt.Sprintf("Deleted %q", selected.title), reload: true}
+		},
+	})
+}
+
+func (m *model) askRotate() {
+	selected, ok := m.current().selected(m.query())
+	if !ok {
+		return
+	}
+	key, ok := selected.value.(*domain.AccessKeyPermissions)
+	if !ok {
+		return
+	}
+
+	m.ask(&pendingAction{
+		question: fmt.Sprintf("Rotate the service key pair of %q?", selected.title),
+		progress: fmt.Sprintf("Rotating %q...", selected.title),
+		run: func() tea.Msg {
+			keyPair, err := m.client.UpdateServiceKeyPair(m.ctx, key.Key)
+			if err != nil {
+				return actionDoneMsg{err: err}
+			}
+			data, err := json.MarshalIndent(keyPair, "", "  ")
+			if err != nil {
+				return actionDoneMsg{err: err}
+			}
+			return actionDoneMsg{
+				status: fmt.Sprintf("Rotated %q; store the new private key now, it is not shown again", selected.title),
+				detail: string(data),
+			}
+		},
+	})
+}
+
+// editSelected opens the selected event's payload in $EDITOR and saves it
+// when the editor exits.
+func (m *model) editSelected() tea.Cmd {
+	selected, ok := m.current().selected(m.query())
+	if !ok {
+		return nil
+	}
+	event, ok := selected.value.(*domain.Event)
+	if !ok {
+		return nil
+	}
+
+	file, err := writePayloadFile(event.Payload)
+	if err != nil {
+		return func() tea.Msg { return actionDoneMsg{err: err} }
+	}
+
+	return tea.ExecProcess(editor.Command(file), func(err error) tea.Msg {
+		defer func() { _ = os.Remove(file) }()
+		if err != nil {
+			return actionDoneMsg{err: fmt.Errorf("run editor: %w", err)}
+		}
+
+		payload, err := readPayloadFile(file)
+		if err != nil {
+			return actionDoneMsg{err: err}
+		}
+
+		patch := domain.NewEventPatch(event, &domain.Event{Name: event.Name, Payload: payload})
+		if patch.IsEmpty() {
+			return actionDoneMsg{status: fmt.Sprintf("No changes to %q", event.Name)}
+		}
+		if err := m.client.PatchEvent(m.ctx, event.ID, patch); err != nil {
+			return actionDoneMsg{err: err}
+		}
+		return actionDoneMsg{status: fmt.Sprintf("Updated payload of %q", event.Name), reload: true}
+	})
+}
+
+func writePayloadFile(payload map[string]any) (string, error) {
+	if payload == nil {
+		payload = map[string]any{}
+	}
+	data, err := json.MarshalIndent(payload, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("encode payload: %w", err)
+	}
+
+	file, err := os.CreateTemp("", "ensync-payload-*.json")
+	if err != nil {
+		return "", fmt.Errorf("create temp file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return "", fmt.Errorf("write temp file: %w", err)
+	}
+	return file.Name(), nil
+}
+
+func readPayloadFile(path string) (map[string]any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read edited payload: %w", err)
+	}
+
+	var payload map[string]any
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, fmt.Errorf("invalid payload JSON: %w", err)
+	}
+	return payload, nil
+}
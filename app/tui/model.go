@@ -0,0 +1,399 @@
+package tui
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/EnSync-engine/CLI/app/api"
+)
+
+// Run starts the full-screen UI and blocks until the user quits.
+func Run(ctx context.Context, client api.APIClient) error {
+	program := tea.NewProgram(NewModel(ctx, client), tea.WithAltScreen(), tea.WithContext(ctx))
+	_, err := program.Run()
+	return err
+}
+
+type mode int
+
+const (
+	modeBrowse mode = iota
+	modeSearch
+	modeConfirm
+)
+
+type pageLoadedMsg struct {
+	tab        int
+	generation int
+	page       *page
+	err        error
+}
+
+type actionDoneMsg struct {
+	status string
+	detail string
+	reload bool
+	err    error
+}
+
+type model struct {
+	ctx    context.Context
+	client api.APIClient
+
+	tabs   []*tab
+	active int
+
+	mode    mode
+	search  textinput.Model
+	detail  viewport.Model
+	confirm *pendingAction
+
+	// result replaces the detail pane after an action such as a key
+	// rotation, until the selection changes.
+	result string
+	status string
+
+	width  int
+	height int
+}
+
+// NewModel returns the Bubble Tea model behind Run, for driving the UI
+// without a terminal.
+func NewModel(ctx context.Context, client api.APIClient) tea.Model {
+	search := textinput.New()
+	search.Prompt = "/"
+	search.Placeholder = "search"
+
+	return &model{
+		ctx:    ctx,
+		client: client,
+		tabs:   newTabs(client),
+		search: search,
+		detail: viewport.New(0, 0),
+	}
+}
+
+func (m *model) Init() tea.Cmd {
+	return m.loadPage(m.active)
+}
+
+func (m *model) current() *tab {
+	return m.tabs[m.active]
+}
+
+func (m *model) query() string {
+	return m.search.Value()
+}
+
+// listRows is the number of list rows that fit between header and footer.
+func (m *model) listRows() int {
+	return max(m.height-4, 1)
+}
+
+func (m *model) loadPage(index int) tea.Cmd {
+	t := m.tabs[index]
+	if t.loading || t.done {
+		return nil
+	}
+	t.loading = true
+
+	params := api.DefaultListParams()
+	params.PageIndex = t.nextPage
+	params.Limit = pageSize
+
+	generation := t.generation
+	return func() tea.Msg {
+		p, err := t.fetch(m.ctx, params)
+		return pageLoadedMsg{tab: index, generation: generation, page: p, err: err}
+	}
+}
+
+// loadMoreIfNeeded fetches the next page when the visible rows would run
+// out, which also keeps incremental search fed with results.
+func (m *model) loadMoreIfNeeded() tea.Cmd {
+	if m.current().needsMore(m.query(), m.listRows()) {
+		return m.loadPage(m.active)
+	}
+	return nil
+}
+
+func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.refreshDetail()
+		return m, m.loadMoreIfNeeded()
+
+	case pageLoadedMsg:
+		t := m.tabs[msg.tab]
+		if msg.generation != t.generation {
+			// Requested before a reload; the reload fetches its own.
+			return m, nil
+		}
+		if msg.err != nil {
+			t.loading = false
+			t.err = msg.err
+			m.status = msg.err.Error()
+			return m, nil
+		}
+		t.appendPage(msg.page)
+		m.refreshDetail()
+		return m, m.loadMoreIfNeeded()
+
+	case actionDoneMsg:
+		if msg.err != nil {
+			m.status = "Error: " + msg.err.Error()
+			return m, nil
+		}
+		m.status = msg.status
+		m.result = msg.detail
+		if msg.reload {
+			m.current().reset()
+			m.refreshDetail()
+			return m, m.loadPage(m.active)
+		}
+		m.refreshDetail()
+		return m, nil
+
+	case tea.KeyMsg:
+		switch m.mode {
+		case modeSearch:
+			return m.updateSearch(msg)
+		case modeConfirm:
+			return m.updateConfirm(msg)
+		default:
+			return m.updateBrowse(msg)
+		}
+	}
+
+	return m, nil
+}
+
+func (m *model) updateBrowse(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	t := m.current()
+
+	switch msg.String() {
+	case "q", "ctrl+c":
+		return m, tea.Quit
+	case "tab", "right", "l":
+		m.switchTab(1)
+		return m, m.loadMoreIfNeeded()
+	case "shift+tab", "left", "h":
+		m.switchTab(-1)
+		return m, m.loadMoreIfNeeded()
+	case "up", "k":
+		m.moveCursor(-1)
+	case "down", "j":
+		m.moveCursor(1)
+	case "pgup":
+		m.moveCursor(-m.listRows())
+	case "pgdown":
+		m.moveCursor(m.listRows())
+	case "ctrl+u":
+		m.detail.HalfPageUp()
+	case "ctrl+d":
+		m.detail.HalfPageDown()
+	case "/":
+		m.mode = modeSearch
+		m.search.Focus()
+		return m, textinput.Blink
+	case "esc":
+		m.search.SetValue("")
+		t.cursor, t.offset = 0, 0
+		m.refreshDetail()
+	case "ctrl+r":
+		t.reset()
+		m.status = "Reloading " + t.name
+		m.refreshDetail()
+		return m, m.loadPage(m.active)
+	case "e":
+		return m, m.editSelected()
+	case "r":
+		m.askRotate()
+	case "d":
+		m.askDelete()
+	}
+
+	return m, m.loadMoreIfNeeded()
+}
+
+func (m *model) updateSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		m.mode = modeBrowse
+		m.search.Blur()
+		return m, nil
+	case "esc":
+		m.mode = modeBrowse
+		m.search.Blur()
+		m.search.SetValue("")
+	}
+
+	var cmd tea.Cmd
+	m.search, cmd = m.search.Update(msg)
+
+	t := m.current()
+	t.cursor, t.offset = 0, 0
+	m.refreshDetail()
+
+	return m, tea.Batch(cmd, m.loadMoreIfNeeded())
+}
+
+func (m *model) updateConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	action := m.confirm
+	m.confirm = nil
+	m.mode = modeBrowse
+
+	if msg.String() != "y" && msg.String() != "Y" {
+		m.status = "Cancelled"
+		return m, nil
+	}
+
+	m.status = action.progress
+	return m, action.run
+}
+
+func (m *model) switchTab(delta int) {
+	m.active = (m.active + delta + len(m.tabs)) % len(m.tabs)
+	m.search.SetValue("")
+	m.status = ""
+	m.refreshDetail()
+}
+
+func (m *model) moveCursor(delta int) {
+	m.current().moveCursor(delta, m.query(), m.listRows())
+	m.refreshDetail()
+}
+
+// refreshDetail renders the selected item into the detail pane.
+func (m *model) refreshDetail() {
+	m.detail.Width = m.width - m.listWidth() - 3
+	m.detail.Height = m.listRows()
+
+	if m.result != "" {
+		m.detail.SetContent(m.result)
+		m.result = ""
+		return
+	}
+
+	selected, ok := m.current().selected(m.query())
+	if !ok {
+		m.detail.SetContent("")
+		return
+	}
+
+	data, err := json.MarshalIndent(selected.value, "", "  ")
+	if err != nil {
+		m.detail.SetContent(err.Error())
+		return
+	}
+	m.detail.SetContent(string(data))
+	m.detail.GotoTop()
+}
+
+func (m *model) listWidth() int {
+	return max(m.width*2/5, 20)
+}
+
+var (
+	activeTabStyle   = lipgloss.NewStyle().Bold(true).Underline(true).Padding(0, 1)
+	inactiveTabStyle = lipgloss.NewStyle().Faint(true).Padding(0, 1)
+	selectedRowStyle = lipgloss.NewStyle().Reverse(true)
+	paneStyle        = lipgloss.NewStyle().BorderStyle(lipgloss.NormalBorder()).BorderLeft(true).PaddingLeft(1)
+	footerStyle      = lipgloss.NewStyle().Faint(true)
+)
+
+func (m *model) View() string {
+	if m.width == 0 {
+		return "Loading..."
+	}
+
+	var header []string
+	for i, t := range m.tabs {
+		style := inactiveTabStyle
+		if i == m.active {
+			style = activeTabStyle
+		}
+		header = append(header, style.Render(fmt.Sprintf("%d %s", i+1, t.name)))
+	}
+
+	list := lipgloss.NewStyle().Width(m.listWidth()).Height(m.listRows()).Render(m.renderList())
+	detail := paneStyle.Height(m.listRows()).Render(m.detail.View())
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		lipgloss.JoinHorizontal(lipgloss.Top, header...),
+		lipgloss.JoinHorizontal(lipgloss.Top, list, detail),
+		m.renderSearch(),
+		footerStyle.Render(m.footer()),
+	)
+}
+
+func (m *model) renderList() string {
+	t := m.current()
+	items := t.visible(m.query())
+	rows := m.listRows()
+
+	var b strings.Builder
+	for i := t.offset; i < len(items) && i < t.offset+rows; i++ {
+		line := truncate(items[i].title, m.listWidth()-2)
+		if i == t.cursor {
+			line = selectedRowStyle.Render(line)
+		}
+		b.WriteString(line + "\n")
+	}
+
+	switch {
+	case t.err != nil:
+		b.WriteString("Error: " + t.err.Error())
+	case t.loading:
+		b.WriteString("Loading...")
+	case len(items) == 0:
+		b.WriteString("No results")
+	}
+
+	return b.String()
+}
+
+func (m *model) renderSearch() string {
+	if m.mode == modeSearch || m.query() != "" {
+		return m.search.View()
+	}
+	return ""
+}
+
+func (m *model) footer() string {
+	if m.mode == modeConfirm {
+		return m.confirm.question + " [y/N]"
+	}
+
+	help := "tab switch • ↑/↓ move • / search • ctrl+r reload • d delete • q quit"
+	switch m.current().kind {
+	case tabEvents:
+		help = "tab switch • ↑/↓ move • / search • e edit payload • d delete • ctrl+r reload • q quit"
+	case tabAccessKeys:
+		help = "tab switch • ↑/↓ move • / search • r rotate • d delete • ctrl+r reload • q quit"
+	}
+
+	if m.status != "" {
+		return m.status + "  |  " + help
+	}
+	return help
+}
+
+func truncate(s string, width int) string {
+	if width <= 1 || lipgloss.Width(s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	if len(runes) > width-1 {
+		runes = runes[:width-1]
+	}
+	return string(runes) + "…"
+}
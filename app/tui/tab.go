@@ -0,0 +1,201 @@
+package tui
+
+import (
+	"context"
+	"strings"
+
+	"github.com/EnSync-engine/CLI/app/api"
+	"github.com/EnSync-engine/CLI/app/domain"
+)
+
+const pageSize = 50
+
+type tabKind int
+
+const (
+	tabEvents tabKind = iota
+	tabAccessKeys
+	tabWorkspaces
+)
+
+// item is a single row in a tab. value holds the domain object shown in
+// the detail pane and passed to actions.
+type item struct {
+	id    string
+	title string
+	value any
+}
+
+// page is one page of list results. results counts the top-level entries
+// returned by the server, which differs from len(items) when nested
+// workspaces are flattened.
+type page struct {
+	items   []item
+	results int
+	total   int
+}
+
+type fetchFunc func(ctx context.Context, params *api.ListParams) (*page, error)
+
+// tab is one resource list. Pages are fetched lazily as the cursor
+// approaches the end of what has been loaded.
+type tab struct {
+	kind  tabKind
+	name  string
+	fetch fetchFunc
+
+	items    []item
+	total    int // top-level results fetched so far
+	nextPage int
+	loading  bool
+	done     bool
+	cursor   int
+	offset   int
+	err      error
+
+	// generation is bumped by reset, so pages requested before it are
+	// recognised and dropped when they arrive.
+	generation int
+}
+
+func newTabs(client api.APIClient) []*tab {
+	return []*tab{
+		{kind: tabEvents, name: "Events", fetch: fetchEvents(client)},
+		{kind: tabAccessKeys, name: "Access Keys", fetch: fetchAccessKeys(client)},
+		{kind: tabWorkspaces, name: "Workspaces", fetch: fetchWorkspaces(client)},
+	}
+}
+
+func (t *tab) reset() {
+	t.generation++
+	t.items = nil
+	t.total = 0
+	t.nextPage = 0
+	t.loading = false
+	t.done = false
+	t.cursor = 0
+	t.offset = 0
+	t.err = nil
+}
+
+func (t *tab) appendPage(p *page) {
+	t.items = append(t.items, p.items...)
+	t.total += p.results
+	t.nextPage++
+	t.loading = false
+	t.done = p.results < pageSize || t.total >= p.total
+}
+
+// visible returns the items matching the search query.
+func (t *tab) visible(query string) []item {
+	if query == "" {
+		return t.items
+	}
+
+	query = strings.ToLower(query)
+	var matches []item
+	for _, it := range t.items {
+		if strings.Contains(strings.ToLower(it.title), query) || strings.Contains(strings.ToLower(it.id), query) {
+			matches = append(matches, it)
+		}
+	}
+	return matches
+}
+
+func (t *tab) selected(query string) (item, bool) {
+	items := t.visible(query)
+	if t.cursor < 0 || t.cursor >= len(items) {
+		return item{}, false
+	}
+	return items[t.cursor], true
+}
+
+// needsMore reports whether another page should be fetched so that at
+// least rows matching items are available below the cursor.
+func (t *tab) needsMore(query string, rows int) bool {
+	if t.loading || t.done || t.err != nil {
+		return false
+	}
+	return len(t.visible(query))-t.cursor <= rows
+}
+
+func (t *tab) moveCursor(delta int, query string, rows int) {
+	count := len(t.visible(query))
+	t.cursor = clamp(t.cursor+delta, 0, count-1)
+
+	if t.cursor < t.offset {
+		t.offset = t.cursor
+	}
+	if rows > 0 && t.cursor >= t.offset+rows {
+		t.offset = t.cursor - rows + 1
+	}
+}
+
+func clamp(v, low, high int) int {
+	if v > high {
+		v = high
+	}
+	if v < low {
+		v = low
+	}
+	return v
+}
+
+func fetchEvents(client api.EventService) fetchFunc {
+	return func(ctx context.Context, params *api.ListParams) (*page, error) {
+		list, err := client.ListEvents(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+
+		items := make([]item, 0, len(list.Results))
+		for _, event := range list.Results {
+			items = append(items, item{id: event.ID, title: event.Name, value: event})
+		}
+		return &page{items: items, results: len(list.Results), total: list.ResultsLength}, nil
+	}
+}
+
+func fetchAccessKeys(client api.AccessKeyService) fetchFunc {
+	return func(ctx context.Context, params *api.ListParams) (*page, error) {
+		list, err := client.ListAccessKeys(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+
+		items := make([]item, 0, len(list.Results))
+		for _, key := range list.Results {
+			title := key.Name
+			if title == "" {
+				title = key.ID
+			}
+			items = append(items, item{id: key.ID, title: title, value: key})
+		}
+		return &page{items: items, results: len(list.Results), total: list.ResultsLength}, nil
+	}
+}
+
+func fetchWorkspaces(client api.WorkspaceService) fetchFunc {
+	return func(ctx context.Context, params *api.ListParams) (*page, error) {
+		list, err := client.ListWorkspaces(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+
+		var items []item
+		var flatten func(workspaces []*domain.Workspace)
+		flatten = func(workspaces []*domain.Workspace) {
+			for _, ws := range workspaces {
+				title := ws.Path
+				if title == "" {
+					title = ws.Name
+				}
+				items = append(items, item{id: ws.ID, title: title, value: ws})
+				flatten(ws.Children)
+			}
+		}
+		flatten(list.Results)
+
+		return &page{items: items, results: len(list.Results), total: list.ResultsLength}, nil
+	}
+}
@@ -1,13 +1,20 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/EnSync-engine/CLI/app/api"
+	"github.com/EnSync-engine/CLI/app/bulk"
+	"github.com/EnSync-engine/CLI/app/completion"
 	"github.com/EnSync-engine/CLI/app/domain"
+	"github.com/EnSync-engine/CLI/app/secrets"
 )
 
 func newAccessKeyCmd(client *api.Client) *cobra.Command {
@@ -18,21 +25,27 @@ func newAccessKeyCmd(client *api.Client) *cobra.Command {
 		Short: "Manage access keys",
 		Long:  "Commands for listing, creating, and managing access key permissions.",
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-			if accessKey == "" {
-				return fmt.Errorf("--access-key is required")
+			cfg, err := setupClient(client)
+			if err != nil {
+				return err
 			}
-			client.SetAccessKey(accessKey)
+			if _, err := authenticate(client, accessKey); err != nil {
+				return err
+			}
+			client.SetWorkspace(workspaceScope(cfg))
 			return nil
 		},
 	}
 
-	cmd.PersistentFlags().StringVar(&accessKey, "access-key", "", "access key for API authentication (required)")
-	_ = cmd.MarkPersistentFlagRequired("access-key")
+	cmd.PersistentFlags().StringVar(&accessKey, "access-key", "", accessKeyUsage)
 
 	cmd.AddCommand(
 		newAccessKeyListCmd(client),
 		newAccessKeyGetCmd(client),
 		newAccessKeyCreateCmd(client),
+		newAccessKeyUpdateCmd(client),
+		newAccessKeySetStateCmd(client, "disable", "Disable an access key until it is enabled again", domain.AccessKeyStateDisabled),
+		newAccessKeySetStateCmd(client, "enable", "Enable a disabled access key", domain.AccessKeyStateActive),
 		newAccessKeyDeleteCmd(client),
 		newAccessKeyPermissionsCmd(client),
 		newAccessKeyRotateCmd(client),
@@ -49,12 +62,37 @@ func newAccessKeyListCmd(client *api.Client) *cobra.Command {
 		orderBy   string
 		filterKey string
 		name      string
+		expiring  durationValue
+		state     string
+		labels    []string
 	)
 
 	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List access keys",
+		Long: `List access keys one page at a time.
+
+With --expiring-within, --state or --label, every page is fetched and only
+the matching keys are listed, so --page and --limit cannot be used with
+them; --name, --filter-key, --order and --order-by still apply.
+--expiring-within also lists keys already expired, soonest first. Keys past
+their expiry are in the EXPIRED state.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			filter := accessKeyFilter{state: strings.ToUpper(state)}
+			switch filter.state {
+			case "", domain.AccessKeyStateActive, domain.AccessKeyStateDisabled, domain.AccessKeyStateExpired:
+			default:
+				return fmt.Errorf("invalid --state %q: use active, disabled or expired", state)
+			}
+			var err error
+			if filter.labels, err = parseLabelSelector(labels); err != nil {
+				return err
+			}
+			if cmd.Flags().Changed("expiring-within") {
+				deadline := time.Now().Add(time.Duration(expiring))
+				filter.expiresBefore = &deadline
+			}
+
 			params := &api.ListParams{
 				PageIndex: page,
 				Limit:     limit,
@@ -71,6 +109,14 @@ func newAccessKeyListCmd(client *api.Client) *cobra.Command {
 				params.Filter["name"] = name
 			}
 
+			if filter.state != "" || len(filter.labels) > 0 || filter.expiresBefore != nil {
+				keys, err := api.CollectPages(cmd.Context(), params, api.AccessKeyPages(client))
+				if err != nil {
+					return err
+				}
+				return printJSON(cmd.OutOrStdout(), filter.apply(keys, time.Now()))
+			}
+
 			keys, err := client.ListAccessKeys(cmd.Context(), params)
 			if err != nil {
 				return err
@@ -86,15 +132,70 @@ func newAccessKeyListCmd(client *api.Client) *cobra.Command {
 	cmd.Flags().StringVar(&orderBy, "order-by", "key", "field to order by")
 	cmd.Flags().StringVar(&filterKey, "filter-key", "", "filter by access key")
 	cmd.Flags().StringVar(&name, "name", "", "filter by name")
+	cmd.Flags().Var(&expiring, "expiring-within", "only list keys expiring within this long, e.g. 14d")
+	cmd.Flags().StringVar(&state, "state", "", "only list keys in this state (active, disabled or expired)")
+	cmd.Flags().StringArrayVar(&labels, "label", nil, "only list keys with this label, as key=value (repeatable)")
+	for _, paged := range []string{"page", "limit"} {
+		for _, filtered := range []string{"expiring-within", "state", "label"} {
+			cmd.MarkFlagsMutuallyExclusive(paged, filtered)
+		}
+	}
 
 	return cmd
 }
 
+// accessKeyFilter selects the access keys listed with --state, --label and
+// --expiring-within. Zero fields match every key.
+type accessKeyFilter struct {
+	state         string
+	labels        map[string]string
+	expiresBefore *time.Time
+}
+
+// apply returns the keys matching the filter, soonest expiring first when
+// filtering by expiry.
+func (f accessKeyFilter) apply(keys []*domain.AccessKeyPermissions, now time.Time) *domain.AccessKeyList {
+	list := &domain.AccessKeyList{Results: []*domain.AccessKeyPermissions{}}
+	for _, key := range keys {
+		if f.state != "" && key.EffectiveState(now) != f.state {
+			continue
+		}
+		if !key.HasLabels(f.labels) {
+			continue
+		}
+		if f.expiresBefore != nil && !key.ExpiresBefore(*f.expiresBefore) {
+			continue
+		}
+		list.Results = append(list.Results, key)
+	}
+	if f.expiresBefore != nil {
+		sort.SliceStable(list.Results, func(i, j int) bool {
+			return list.Results[i].ExpiresAt.Before(*list.Results[j].ExpiresAt)
+		})
+	}
+	list.ResultsLength = len(list.Results)
+	return list
+}
+
+// parseLabelSelector parses --label values of the form key=value.
+func parseLabelSelector(values []string) (map[string]string, error) {
+	labels := make(map[string]string, len(values))
+	for _, value := range values {
+		name, labelValue, ok := strings.Cut(value, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid label %q: use key=value", value)
+		}
+		labels[name] = labelValue
+	}
+	return labels, nil
+}
+
 func newAccessKeyGetCmd(client *api.Client) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "get [id]",
-		Short: "Get access key by ID",
-		Args:  cobra.ExactArgs(1),
+		Use:               "get [id]",
+		Short:             "Get access key by ID",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeResource(client, completion.AccessKeyIDs),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			key, err := client.GetAccessKeyByID(cmd.Context(), args[0])
 			if err != nil {
@@ -112,12 +213,43 @@ func newAccessKeyCreateCmd(client *api.Client) *cobra.Command {
 		keyType         string
 		name            string
 		permissionsJSON string
+		expiresIn       durationValue
+		expiresAt       string
+		bulkOpts        bulkOptions
+		secretOpts      secretOptions
 	)
 
 	cmd := &cobra.Command{
 		Use:   "create",
 		Short: "Create a new access key",
+		Long: `Create a new access key, or one key per row with --from-file.
+
+NDJSON rows are objects with "name", "type" and "permissions" fields. CSV
+files have a header row with "name", "type", "send" and "receive" columns,
+the event lists separated by ";". Rows without a type use --type. Rows may
+set "expires_at"; the others expire as --expires-in or --expires-at say.
+
+The private key of a SERVICE key's key pair is only returned once. Save it
+with --secret-file, --bundle or --secret-sink, or print it with
+--show-secret. The new access key is printed masked unless --show-secret is
+given; "access-key list" shows it in full. With --from-file, the created keys are written to the
+results file instead, which is only readable by you. Store them somewhere
+safe and delete the file.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			expiry, err := keyExpiry(time.Duration(expiresIn), expiresAt, time.Now())
+			if err != nil {
+				return err
+			}
+
+			if bulkOpts.fromFile != "" {
+				params := map[string]string{"type": keyType}
+				// Stored as a time, so a resumed job keeps the original expiry.
+				if expiry != nil {
+					params["expires_at"] = expiry.Format(time.RFC3339)
+				}
+				return runBulk(cmd, client, &bulkOpts, jobKindCreateAccessKeys, params)
+			}
+
 			var permissions *domain.Permissions
 			if permissionsJSON != "" {
 				if err := json.Unmarshal([]byte(permissionsJSON), &permissions); err != nil {
@@ -125,10 +257,19 @@ func newAccessKeyCreateCmd(client *api.Client) *cobra.Command {
 				}
 			}
 
+			// Only SERVICE keys come with a key pair to save.
+			var sink secrets.Sink
+			if strings.EqualFold(keyType, "SERVICE") || secretOpts.set() {
+				if sink, err = secretOpts.open(cmd); err != nil {
+					return err
+				}
+			}
+
 			req := &domain.CreateAccessKeyRequest{
 				Type:        keyType,
 				Name:        name,
 				Permissions: permissions,
+				ExpiresAt:   expiry,
 			}
 
 			key, err := client.CreateAccessKey(cmd.Context(), req)
@@ -136,23 +277,250 @@ func newAccessKeyCreateCmd(client *api.Client) *cobra.Command {
 				return err
 			}
 
+			secret := secrets.Secret{ID: key.ID, Name: key.Name, AccessKey: key.AccessKey, ServiceKeyPair: key.ServiceKeyPair, IssuedAt: time.Now().UTC()}
+			if err := saveSecret(cmd, sink, secret); err != nil {
+				return err
+			}
+			if !secretOpts.show {
+				key.AccessKey = api.MaskSecret(key.AccessKey)
+				key.ServiceKeyPair = withoutPrivateKey(key.ServiceKeyPair)
+			}
 			return printJSON(cmd.OutOrStdout(), key)
 		},
 	}
 
 	cmd.Flags().StringVar(&keyType, "type", "SERVICE", "access key type (SERVICE or ACCOUNT)")
-	cmd.Flags().StringVar(&name, "name", "", "access key name (required)")
+	cmd.Flags().StringVar(&name, "name", "", "access key name (required unless --from-file is set)")
 	cmd.Flags().StringVar(&permissionsJSON, "permissions", "", `permissions JSON`)
-	_ = cmd.MarkFlagRequired("name")
+	cmd.Flags().Var(&expiresIn, "expires-in", "expire the key after this long, e.g. 90d or 12h (default never)")
+	cmd.Flags().StringVar(&expiresAt, "expires-at", "", "expire the key at this RFC 3339 time or date (default never)")
+	addBulkFlags(cmd, &bulkOpts)
+	addSecretFlags(cmd, &secretOpts)
+	cmd.MarkFlagsOneRequired("name", "from-file")
+	cmd.MarkFlagsMutuallyExclusive("name", "from-file")
+	cmd.MarkFlagsMutuallyExclusive("permissions", "from-file")
+	cmd.MarkFlagsMutuallyExclusive("expires-in", "expires-at")
+	for _, flag := range []string{"secret-file", "bundle", "secret-sink", "show-secret"} {
+		cmd.MarkFlagsMutuallyExclusive(flag, "from-file")
+	}
+
+	return cmd
+}
+
+// keyExpiry returns when a key created now expires given --expires-in or
+// --expires-at, or nil if it never does.
+func keyExpiry(expiresIn time.Duration, expiresAt string, now time.Time) (*time.Time, error) {
+	var expiry time.Time
+	switch {
+	case expiresIn > 0:
+		expiry = now.Add(expiresIn)
+	case expiresAt != "":
+		var err error
+		if expiry, err = parseTime(expiresAt); err != nil {
+			return nil, fmt.Errorf("--expires-at: %w", err)
+		}
+	default:
+		return nil, nil
+	}
+
+	if !expiry.After(now) {
+		return nil, fmt.Errorf("expiry %s is not in the future", expiry.Format(time.RFC3339))
+	}
+	expiry = expiry.UTC().Truncate(time.Second)
+	return &expiry, nil
+}
+
+// createAccessKeyTask creates the access key described by each --from-file
+// row. The "type" and "expires_at" params are used for rows that do not set
+// them.
+func createAccessKeyTask(client *api.Client, params map[string]string) bulk.Task {
+	return func(ctx context.Context, record bulk.Record) (string, any, error) {
+		req, err := accessKeyRequestFromRecord(record, params["type"])
+		if err != nil {
+			return req.Name, nil, err
+		}
+		if req.ExpiresAt == nil && params["expires_at"] != "" {
+			expiry, err := time.Parse(time.RFC3339, params["expires_at"])
+			if err != nil {
+				return req.Name, nil, fmt.Errorf("invalid expires_at param: %w", err)
+			}
+			req.ExpiresAt = &expiry
+		}
+		key, err := client.CreateAccessKey(ctx, req)
+		if err != nil {
+			return req.Name, nil, err
+		}
+		return req.Name, key, nil
+	}
+}
+
+// accessKeyRequestFromRecord converts a --from-file row into a create
+// request. NDJSON rows carry a "permissions" object; CSV rows carry "send"
+// and "receive" columns instead.
+func accessKeyRequestFromRecord(record bulk.Record, defaultType string) (*domain.CreateAccessKeyRequest, error) {
+	req := &domain.CreateAccessKeyRequest{Type: defaultType}
+
+	name, err := record.String("name")
+	if err != nil {
+		return req, err
+	}
+	if name == "" {
+		return req, fmt.Errorf("name is required")
+	}
+	req.Name = name
+
+	keyType, err := record.String("type")
+	if err != nil {
+		return req, err
+	}
+	if keyType != "" {
+		req.Type = keyType
+	}
+
+	expiresAt, err := record.String("expires_at")
+	if err != nil {
+		return req, err
+	}
+	if expiresAt != "" {
+		expiry, err := parseTime(expiresAt)
+		if err != nil {
+			return req, err
+		}
+		req.ExpiresAt = &expiry
+	}
+
+	if object, err := record.Object("permissions"); err != nil {
+		return req, err
+	} else if object != nil {
+		data, err := json.Marshal(object)
+		if err != nil {
+			return req, err
+		}
+		if err := json.Unmarshal(data, &req.Permissions); err != nil {
+			return req, fmt.Errorf("invalid permissions: %w", err)
+		}
+		return req, nil
+	}
+
+	send, err := record.List("send")
+	if err != nil {
+		return req, err
+	}
+	receive, err := record.List("receive")
+	if err != nil {
+		return req, err
+	}
+	if send != nil || receive != nil {
+		req.Permissions = &domain.Permissions{Send: send, Receive: receive}
+	}
+
+	return req, nil
+}
+
+func newAccessKeyUpdateCmd(client *api.Client) *cobra.Command {
+	var (
+		name        string
+		description string
+		labels      []string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "update [id]",
+		Short: "Update an access key's name, description or labels",
+		Long: `Update an access key's name, description or labels. Only the given
+fields change.
+
+--label takes key=value to set a label and key- to remove one, and may be
+repeated. Values are taken as given, commas included.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeResource(client, completion.AccessKeyIDs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			req := &domain.UpdateAccessKeyRequest{}
+			if cmd.Flags().Changed("name") {
+				if name == "" {
+					return fmt.Errorf("--name cannot be empty")
+				}
+				req.Name = &name
+			}
+			if cmd.Flags().Changed("description") {
+				req.Description = &description
+			}
+			if len(labels) > 0 {
+				patch, err := parseLabelPatch(labels)
+				if err != nil {
+					return err
+				}
+				req.Labels = patch
+			}
+			if req.IsEmpty() {
+				return fmt.Errorf("nothing to update: set --name, --description or --label")
+			}
+
+			if err := client.UpdateAccessKey(cmd.Context(), args[0], req); err != nil {
+				return err
+			}
+
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Access key %q updated successfully\n", args[0])
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&name, "name", "", "new name")
+	cmd.Flags().StringVar(&description, "description", "", "new description; empty clears it")
+	cmd.Flags().StringArrayVar(&labels, "label", nil, "set a label as key=value, or remove one as key- (repeatable)")
+
+	return cmd
+}
+
+// parseLabelPatch parses --label values into a merge patch: key=value sets
+// a label and key- removes it.
+func parseLabelPatch(values []string) (map[string]*string, error) {
+	patch := make(map[string]*string, len(values))
+	for _, value := range values {
+		if name, ok := strings.CutSuffix(value, "-"); ok && !strings.Contains(value, "=") {
+			if name == "" {
+				return nil, fmt.Errorf("invalid label %q: use key=value or key-", value)
+			}
+			patch[name] = nil
+			continue
+		}
+		name, labelValue, ok := strings.Cut(value, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid label %q: use key=value or key-", value)
+		}
+		patch[name] = &labelValue
+	}
+	return patch, nil
+}
+
+// newAccessKeySetStateCmd returns the command putting an access key in the
+// given state. Disabled keys are rejected by the server until enabled again.
+func newAccessKeySetStateCmd(client *api.Client, use, short, state string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               use + " [id]",
+		Short:             short,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeResource(client, completion.AccessKeyIDs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			req := &domain.UpdateAccessKeyRequest{State: &state}
+			if err := client.UpdateAccessKey(cmd.Context(), args[0], req); err != nil {
+				return err
+			}
+
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Access key %q %sd successfully\n", args[0], use)
+			return nil
+		},
+	}
 
 	return cmd
 }
 
 func newAccessKeyDeleteCmd(client *api.Client) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "delete [id]",
-		Short: "Delete an access key",
-		Args:  cobra.ExactArgs(1),
+		Use:               "delete [id]",
+		Short:             "Delete an access key",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeResource(client, completion.AccessKeyIDs),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if err := client.DeleteAccessKey(cmd.Context(), args[0]); err != nil {
 				return err
@@ -182,9 +550,10 @@ func newAccessKeyPermissionsCmd(client *api.Client) *cobra.Command {
 
 func newAccessKeyGetPermissionsCmd(client *api.Client) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "get [key]",
-		Short: "Get permissions for an access key",
-		Args:  cobra.ExactArgs(1),
+		Use:               "get [key]",
+		Short:             "Get permissions for an access key",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: cobra.NoFileCompletions,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			permissions, err := client.GetAccessKeyPermissions(cmd.Context(), args[0])
 			if err != nil {
@@ -201,9 +570,10 @@ func newAccessKeySetPermissionsCmd(client *api.Client) *cobra.Command {
 	var permissionsJSON string
 
 	cmd := &cobra.Command{
-		Use:   "set [key]",
-		Short: "Set permissions for an access key",
-		Args:  cobra.ExactArgs(1),
+		Use:               "set [key]",
+		Short:             "Set permissions for an access key",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: cobra.NoFileCompletions,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			var permissions domain.Permissions
 			if err := json.Unmarshal([]byte(permissionsJSON), &permissions); err != nil {
@@ -226,18 +596,172 @@ func newAccessKeySetPermissionsCmd(client *api.Client) *cobra.Command {
 }
 
 func newAccessKeyRotateCmd(client *api.Client) *cobra.Command {
+	var (
+		all        bool
+		olderThan  durationValue
+		dryRun     bool
+		secretOpts secretOptions
+	)
+
 	cmd := &cobra.Command{
 		Use:   "rotate [key]",
 		Short: "Rotate service key pair for an access key",
-		Args:  cobra.ExactArgs(1),
+		Long: `Rotate the service key pair of an access key.
+
+The new private key is only returned once. Save it with --secret-file,
+--bundle or --secret-sink, or print it with --show-secret.
+
+With --all, the key pairs of every SERVICE key are rotated, or only those
+issued longer than --older-than ago. The new key pairs are saved as they
+are issued, so a failure does not lose the ones rotated before it, and
+--show-secret prints each one as it is issued. Keys that fail to rotate are
+reported and skipped. Read a bundle back with
+"ensync bundle decrypt".`,
+		Args:              cobra.RangeArgs(0, 1),
+		ValidArgsFunction: cobra.NoFileCompletions,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if all {
+				if len(args) > 0 {
+					return fmt.Errorf("--all does not take a key")
+				}
+				return rotateAll(cmd, client, time.Duration(olderThan), &secretOpts, dryRun)
+			}
+			if len(args) == 0 {
+				return fmt.Errorf("requires a key, or --all")
+			}
+			for _, flag := range []string{"older-than", "dry-run"} {
+				if cmd.Flags().Changed(flag) {
+					return fmt.Errorf("--%s requires --all", flag)
+				}
+			}
+
+			sink, err := secretOpts.open(cmd)
+			if err != nil {
+				return err
+			}
+			secret := secrets.Secret{AccessKey: args[0]}
+			// Sinks name the stored secret after the key.
+			if sink != nil {
+				key, err := client.GetAccessKeyPermissions(cmd.Context(), args[0])
+				if err != nil {
+					return err
+				}
+				secret.ID, secret.Name = key.ID, key.Name
+			}
+
 			keyPair, err := client.UpdateServiceKeyPair(cmd.Context(), args[0])
 			if err != nil {
 				return err
 			}
+			secret.ServiceKeyPair, secret.IssuedAt = keyPair, time.Now().UTC()
+			if err := saveSecret(cmd, sink, secret); err != nil {
+				return err
+			}
+			if !secretOpts.show {
+				keyPair = withoutPrivateKey(keyPair)
+			}
 			return printJSON(cmd.OutOrStdout(), keyPair)
 		},
 	}
 
+	cmd.Flags().BoolVar(&all, "all", false, "rotate the key pairs of all SERVICE keys")
+	cmd.Flags().Var(&olderThan, "older-than", "with --all, only rotate key pairs issued longer ago than this, e.g. 90d")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "with --all, list the keys that would be rotated")
+	addSecretFlags(cmd, &secretOpts)
+
 	return cmd
 }
+
+// rotateAll rotates the key pairs of the SERVICE keys older than olderThan,
+// saving each new key pair as soon as it is issued.
+func rotateAll(cmd *cobra.Command, client *api.Client, olderThan time.Duration, secretOpts *secretOptions, dryRun bool) error {
+	ctx := cmd.Context()
+
+	keys, err := listAllAccessKeys(ctx, client)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	due := &domain.AccessKeyList{Results: []*domain.AccessKeyPermissions{}}
+	for _, key := range keys {
+		if strings.EqualFold(key.Type, "SERVICE") && key.KeyPairAge(now) >= olderThan {
+			due.Results = append(due.Results, key)
+		}
+	}
+	due.ResultsLength = len(due.Results)
+
+	if dryRun {
+		return printJSON(cmd.OutOrStdout(), due)
+	}
+	if len(due.Results) == 0 {
+		_, _ = fmt.Fprintln(cmd.OutOrStdout(), "No key pairs to rotate")
+		return nil
+	}
+
+	sink, err := secretOpts.open(cmd)
+	if err != nil {
+		return err
+	}
+
+	var rotated, failed int
+	for _, key := range due.Results {
+		keyPair, err := client.UpdateServiceKeyPair(ctx, key.Key)
+		if err != nil {
+			failed++
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Failed to rotate %s (%s): %v\n", key.Name, key.ID, err)
+			continue
+		}
+
+		secret := secrets.Secret{
+			ID:             key.ID,
+			Name:           key.Name,
+			AccessKey:      key.Key,
+			ServiceKeyPair: keyPair,
+			IssuedAt:       time.Now().UTC(),
+		}
+		if sink != nil {
+			if err := sink.Put(ctx, secret); err != nil {
+				return fmt.Errorf("save key pair of %s, which was already rotated: %w", key.ID, err)
+			}
+		}
+		// Printed straight away, so an interrupted run has shown every key
+		// pair issued before it stopped.
+		if secretOpts.show {
+			if err := printJSON(cmd.OutOrStdout(), secret); err != nil {
+				return fmt.Errorf("print key pair of %s, which was already rotated: %w", key.ID, err)
+			}
+		}
+		rotated++
+	}
+
+	_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Rotated %d of %d key pairs", rotated, len(due.Results))
+	if sink != nil && rotated > 0 {
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), ", saved to %s", sink)
+	}
+	_, _ = fmt.Fprintln(cmd.ErrOrStderr())
+	if failed > 0 {
+		return fmt.Errorf("%d of %d key pairs failed to rotate", failed, len(due.Results))
+	}
+	return nil
+}
+
+// saveSecret puts a newly issued private key in sink, if there is one.
+func saveSecret(cmd *cobra.Command, sink secrets.Sink, secret secrets.Secret) error {
+	if sink == nil || secret.ServiceKeyPair == nil || secret.ServiceKeyPair.PrivateKey == "" {
+		return nil
+	}
+	if err := sink.Put(cmd.Context(), secret); err != nil {
+		return fmt.Errorf("save private key, which is not shown again; rotate the key pair to issue a new one: %w", err)
+	}
+	_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Saved the private key to %s\n", sink)
+	return nil
+}
+
+// withoutPrivateKey returns a copy of keyPair without its private key, for
+// printing.
+func withoutPrivateKey(keyPair *domain.ServiceKeyPair) *domain.ServiceKeyPair {
+	if keyPair == nil {
+		return nil
+	}
+	return &domain.ServiceKeyPair{PublicKey: keyPair.PublicKey}
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/EnSync-engine/CLI/app/api"
+	"github.com/EnSync-engine/CLI/app/config"
+)
+
+const accessKeyUsage = "access key for API authentication (default from the profile's auth settings or $" + envAccessKey + ")"
+
+// profileAuth authenticates requests as configured for the profile, nil if
+// the profile configures no auth. It is built once by setupClient so that
+// an `ensync shell` session keeps its credentials, e.g. an OAuth2 token,
+// between commands.
+var profileAuth api.Authenticator
+
+// authenticator returns the authenticator configured by settings, or nil if
+// there is none.
+func authenticator(settings config.AuthSettings) (api.Authenticator, error) {
+	set := 0
+	for _, isSet := range []bool{settings.AccessKey != "", settings.KeyFile != "", settings.KeyCommand != "", settings.OAuth2.IsSet()} {
+		if isSet {
+			set++
+		}
+	}
+	if set > 1 {
+		return nil, errors.New("auth: set only one of access_key, key_file, key_command and oauth2")
+	}
+
+	switch {
+	case settings.AccessKey != "":
+		return api.StaticKey(settings.AccessKey), nil
+	case settings.KeyFile != "":
+		return api.NewKeyFileAuthenticator(settings.KeyFile), nil
+	case settings.KeyCommand != "":
+		return api.NewKeyCommandAuthenticator(settings.KeyCommand), nil
+	case settings.OAuth2.IsSet():
+		auth, err := api.NewOAuth2Authenticator(api.OAuth2Options{
+			TokenURL:     settings.OAuth2.TokenURL,
+			ClientID:     settings.OAuth2.ClientID,
+			ClientSecret: settings.OAuth2.ClientSecret,
+			Scopes:       settings.OAuth2.Scopes,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("auth.oauth2: %w", err)
+		}
+		return auth, nil
+	default:
+		return nil, nil
+	}
+}
+
+// authenticate sets how the client authenticates: with accessKey (the
+// --access-key flag) if given, otherwise as configured for the profile,
+// otherwise with $ENSYNC_ACCESS_KEY. It returns the authenticator used,
+// which is nil when none is given and signing.omit_access_key makes the
+// signing key pair identify the caller instead.
+func authenticate(client *api.Client, accessKey string) (api.Authenticator, error) {
+	var auth api.Authenticator
+	switch {
+	case accessKey != "":
+		auth = api.StaticKey(accessKey)
+	case profileAuth != nil:
+		auth = profileAuth
+	case os.Getenv(envAccessKey) != "":
+		auth = api.StaticKey(os.Getenv(envAccessKey))
+	case loadedConfig != nil && loadedConfig.Signing.OmitAccessKey != nil && *loadedConfig.Signing.OmitAccessKey:
+		// The access key would not be sent anyway.
+	default:
+		return nil, errors.New("--access-key is required unless the profile configures auth or " + envAccessKey + " is set")
+	}
+
+	client.SetAuthenticator(auth)
+	return auth, nil
+}
@@ -0,0 +1,310 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/EnSync-engine/CLI/app/api"
+	"github.com/EnSync-engine/CLI/app/bulk"
+	"github.com/EnSync-engine/CLI/app/config"
+	"github.com/EnSync-engine/CLI/app/jobs"
+)
+
+const (
+	defaultBulkWorkers = 4
+	resultsFileSuffix  = ".results.ndjson"
+	stdinFile          = "-"
+	jobsDir            = "jobs"
+
+	// cancelPollInterval is how often a running job checks whether it was
+	// cancelled with `ensync jobs cancel`.
+	cancelPollInterval = time.Second
+)
+
+// bulkKind describes one kind of bulk job: what its rows create and how a
+// row is processed. Jobs store their kind so they can be resumed later.
+type bulkKind struct {
+	noun string
+	task func(client *api.Client, params map[string]string) bulk.Task
+}
+
+const (
+	jobKindCreateEvents     = "event-create"
+	jobKindCreateAccessKeys = "access-key-create"
+)
+
+var bulkKinds = map[string]bulkKind{
+	jobKindCreateEvents:     {noun: "events", task: createEventTask},
+	jobKindCreateAccessKeys: {noun: "access keys", task: createAccessKeyTask},
+}
+
+// bulkOptions are the flags shared by commands that accept --from-file.
+type bulkOptions struct {
+	fromFile        string
+	format          string
+	resultsFile     string
+	workers         int
+	continueOnError bool
+}
+
+func addBulkFlags(cmd *cobra.Command, opts *bulkOptions) {
+	cmd.Flags().StringVar(&opts.fromFile, "from-file", "", `create one per row of an NDJSON or CSV file ("-" for stdin)`)
+	cmd.Flags().StringVar(&opts.format, "format", "", "input format: ndjson or csv (default from the file extension)")
+	cmd.Flags().StringVar(&opts.resultsFile, "results", "", "file to write per-row results to as NDJSON (default <file>"+resultsFileSuffix+")")
+	cmd.Flags().IntVar(&opts.workers, "workers", defaultBulkWorkers, "number of rows processed concurrently")
+	cmd.Flags().BoolVar(&opts.continueOnError, "continue-on-error", false, "keep going after a row fails")
+	_ = cmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions([]string{string(bulk.FormatNDJSON), string(bulk.FormatCSV)}, cobra.ShellCompDirectiveNoFileComp))
+}
+
+func jobStore() *jobs.Store {
+	return jobs.NewStore(filepath.Join(config.Dir(), jobsDir))
+}
+
+// runBulk starts a job of the given kind for every record in
+// opts.fromFile. params are stored with the job and passed to its task.
+func runBulk(cmd *cobra.Command, client *api.Client, opts *bulkOptions, kind string, params map[string]string) error {
+	records, err := readBulkInput(cmd, opts)
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		return fmt.Errorf("no rows found in %s", opts.fromFile)
+	}
+
+	resultsFile := opts.resultsFile
+	if resultsFile == "" {
+		resultsFile = opts.fromFile + resultsFileSuffix
+		if opts.fromFile == stdinFile {
+			resultsFile = "ensync" + resultsFileSuffix
+		}
+	}
+	if resultsFile, err = filepath.Abs(resultsFile); err != nil {
+		return err
+	}
+
+	job := &jobs.Job{
+		Kind:            kind,
+		Source:          opts.fromFile,
+		ResultsFile:     resultsFile,
+		Workers:         opts.workers,
+		ContinueOnError: opts.continueOnError,
+		Params:          params,
+	}
+	if loadedConfig != nil {
+		job.BaseURL = loadedConfig.BaseURL
+		job.Workspace = workspaceScope(loadedConfig)
+	}
+
+	store := jobStore()
+	if err := store.Create(job, records); err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Started job %s\n", job.ID)
+
+	return runJob(cmd, client, store, job)
+}
+
+// runJob processes the job's records that have not succeeded yet,
+// journaling each result, then writes the merged results file. Outputs are
+// kept out of the journal, so those of earlier runs are taken from the
+// results file they wrote. Every row
+// is sent with an idempotency key derived from the job ID and its line, so
+// a row replayed after a crash is not created twice.
+func runJob(cmd *cobra.Command, client *api.Client, store *jobs.Store, job *jobs.Job) error {
+	kind, ok := bulkKinds[job.Kind]
+	if !ok {
+		return fmt.Errorf("job %s has unknown kind %q", job.ID, job.Kind)
+	}
+
+	records, err := store.Records(job)
+	if err != nil {
+		return err
+	}
+	previous, err := store.Results(job)
+	if err != nil {
+		return err
+	}
+	var pending []bulk.Record
+	for i, record := range records {
+		if previous[i].Status != bulk.StatusSucceeded {
+			pending = append(pending, record)
+		}
+	}
+
+	journal, err := store.OpenJournal(job)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = journal.Close() }()
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+	defer stop()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go watchCancellation(ctx, store, job.ID, cancel)
+
+	outputs := previousOutputs(job.ResultsFile)
+	task := kind.task(client, job.Params)
+	out := cmd.OutOrStdout()
+	runner := &bulk.Runner{
+		Workers:         job.Workers,
+		ContinueOnError: job.ContinueOnError,
+		OnResult: func(result bulk.Result) {
+			if result.Output != nil {
+				outputs[result.Line] = result.Output
+			}
+			switch result.Status {
+			case bulk.StatusSucceeded:
+				_, _ = fmt.Fprintf(out, "line %d: created %q\n", result.Line, result.Name)
+			case bulk.StatusFailed:
+				_, _ = fmt.Fprintf(out, "line %d: failed %q: %s\n", result.Line, result.Name, result.Error)
+			}
+			if result.Status != bulk.StatusSkipped {
+				if err := journal.Append(result); err != nil {
+					_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Warning: could not record line %d in the job journal: %v\n", result.Line, err)
+				}
+			}
+		},
+	}
+	runner.Run(ctx, pending, func(ctx context.Context, record bulk.Record) (string, any, error) {
+		ctx = api.WithIdempotencyKey(ctx, fmt.Sprintf("%s-%d", job.ID, record.Line))
+		return task(ctx, record)
+	})
+
+	results, err := store.Results(job)
+	if err != nil {
+		return err
+	}
+	for i, result := range results {
+		if result.Status == bulk.StatusSucceeded {
+			results[i].Output = outputs[result.Line]
+		}
+	}
+	if err := writeResults(job.ResultsFile, results); err != nil {
+		return err
+	}
+
+	succeeded := bulk.Count(results, bulk.StatusSucceeded)
+	failed := bulk.Count(results, bulk.StatusFailed)
+	skipped := bulk.Count(results, bulk.StatusSkipped)
+
+	// A job cancelled from another process stays cancelled.
+	if latest, err := store.Get(job.ID); err == nil && latest.Status == jobs.StatusCancelled {
+		job.Status = jobs.StatusCancelled
+	} else {
+		switch {
+		case failed == 0 && skipped == 0:
+			job.Status = jobs.StatusCompleted
+		case ctx.Err() != nil:
+			job.Status = jobs.StatusInterrupted
+		default:
+			job.Status = jobs.StatusFailed
+		}
+	}
+	if err := store.Save(job); err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(out, "Created %d of %d %s", succeeded, len(results), kind.noun)
+	if failed > 0 || skipped > 0 {
+		_, _ = fmt.Fprintf(out, " (%d failed, %d skipped)", failed, skipped)
+	}
+	_, _ = fmt.Fprintf(out, "\nResults written to %s\n", job.ResultsFile)
+
+	switch job.Status {
+	case jobs.StatusCompleted:
+		return nil
+	case jobs.StatusCancelled:
+		return fmt.Errorf("job %s was cancelled", job.ID)
+	default:
+		_, _ = fmt.Fprintf(out, "Resume with: ensync jobs resume %s --access-key <key>\n", job.ID)
+		return fmt.Errorf("job %s %s: %d of %d rows not created", job.ID, job.Status, failed+skipped, len(results))
+	}
+}
+
+// watchCancellation calls cancel once the job is marked cancelled.
+func watchCancellation(ctx context.Context, store *jobs.Store, id string, cancel context.CancelFunc) {
+	ticker := time.NewTicker(cancelPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if job, err := store.Get(id); err == nil && job.Status == jobs.StatusCancelled {
+				cancel()
+				return
+			}
+		}
+	}
+}
+
+func readBulkInput(cmd *cobra.Command, opts *bulkOptions) ([]bulk.Record, error) {
+	format, err := bulk.ParseFormat(opts.format, opts.fromFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var in io.Reader = cmd.InOrStdin()
+	if opts.fromFile != stdinFile {
+		file, err := os.Open(opts.fromFile)
+		if err != nil {
+			return nil, fmt.Errorf("open input: %w", err)
+		}
+		defer func() { _ = file.Close() }()
+		in = file
+	}
+
+	return bulk.Read(in, format)
+}
+
+// previousOutputs returns the outputs of the succeeded rows in a results
+// file written earlier, by line. A missing or unreadable file has none.
+func previousOutputs(path string) map[int]any {
+	outputs := make(map[int]any)
+	file, err := os.Open(path)
+	if err != nil {
+		return outputs
+	}
+	defer func() { _ = file.Close() }()
+
+	decoder := json.NewDecoder(file)
+	for {
+		var result bulk.Result
+		if err := decoder.Decode(&result); err != nil {
+			return outputs
+		}
+		if result.Status == bulk.StatusSucceeded && result.Output != nil {
+			outputs[result.Line] = result.Output
+		}
+	}
+}
+
+// writeResults writes one JSON object per result. Results may contain
+// newly created secrets, so the file is only readable by the owner.
+func writeResults(path string, results []bulk.Result) error {
+	var b strings.Builder
+	for _, result := range results {
+		data, err := json.Marshal(result)
+		if err != nil {
+			return fmt.Errorf("encode results: %w", err)
+		}
+		b.Write(data)
+		b.WriteByte('\n')
+	}
+
+	if err := os.WriteFile(path, []byte(b.String()), 0o600); err != nil {
+		return fmt.Errorf("write results: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/spf13/cobra"
+
+	"github.com/EnSync-engine/CLI/app/bundle"
+)
+
+const envBundlePassphrase = "ENSYNC_BUNDLE_PASSPHRASE"
+
+const passphraseFileUsage = "file holding the bundle passphrase (default $" + envBundlePassphrase + " or a prompt)"
+
+func newBundleCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bundle",
+		Short: "Work with encrypted secret bundles",
+		Long: `Bundles are files holding secrets, such as the key pairs written by
+"access-key rotate --all", encrypted with a passphrase.`,
+	}
+
+	cmd.AddCommand(newBundleDecryptCmd())
+
+	return cmd
+}
+
+func newBundleDecryptCmd() *cobra.Command {
+	var passphraseFile string
+
+	cmd := &cobra.Command{
+		Use:   "decrypt <file>",
+		Short: "Print the decrypted contents of a bundle",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			passphrase, err := readPassphrase(cmd, passphraseFile, false)
+			if err != nil {
+				return err
+			}
+			plaintext, err := bundle.ReadFile(args[0], passphrase)
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintln(cmd.OutOrStdout(), strings.TrimRight(string(plaintext), "\n"))
+			return err
+		},
+	}
+
+	cmd.Flags().StringVar(&passphraseFile, "passphrase-file", "", passphraseFileUsage)
+
+	return cmd
+}
+
+// readPassphrase returns the bundle passphrase from path, from
+// $ENSYNC_BUNDLE_PASSPHRASE or, when stdin is a terminal, from a prompt.
+// A new passphrase is prompted for twice.
+func readPassphrase(cmd *cobra.Command, path string, isNew bool) (string, error) {
+	var passphrase string
+	switch {
+	case path != "":
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("read passphrase file %q: %w", path, err)
+		}
+		passphrase = strings.TrimRight(string(data), "\r\n")
+	case os.Getenv(envBundlePassphrase) != "":
+		passphrase = os.Getenv(envBundlePassphrase)
+	case term.IsTerminal(os.Stdin.Fd()):
+		var err error
+		if passphrase, err = promptPassphrase(cmd, "Bundle passphrase: "); err != nil {
+			return "", err
+		}
+		if isNew {
+			again, err := promptPassphrase(cmd, "Repeat passphrase: ")
+			if err != nil {
+				return "", err
+			}
+			if again != passphrase {
+				return "", fmt.Errorf("passphrases do not match")
+			}
+		}
+	default:
+		return "", fmt.Errorf("no bundle passphrase: use --passphrase-file or set %s", envBundlePassphrase)
+	}
+
+	if isNew && len(passphrase) < bundle.MinPassphraseLength {
+		return "", bundle.ErrShortPassphrase
+	}
+	return passphrase, nil
+}
+
+func promptPassphrase(cmd *cobra.Command, prompt string) (string, error) {
+	_, _ = fmt.Fprint(cmd.ErrOrStderr(), prompt)
+	passphrase, err := term.ReadPassword(os.Stdin.Fd())
+	_, _ = fmt.Fprintln(cmd.ErrOrStderr())
+	if err != nil {
+		return "", fmt.Errorf("read passphrase: %w", err)
+	}
+	return string(passphrase), nil
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/EnSync-engine/CLI/app/api"
+	"github.com/EnSync-engine/CLI/app/config"
+)
+
+const (
+	httpCacheDir    = "cache/http"
+	defaultCacheTTL = 30 * time.Second
+)
+
+func newCacheCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Manage the local response cache",
+		Long: `GET responses are cached under ~/.ensync/cache/http, per access key, and
+reused for their max-age, up to --cache-ttl. After that they are revalidated
+with the server using ETag/Last-Modified. Responses without a max-age are
+revalidated every time, and not stored at all when they cannot be, unless
+--cache-ttl is given. Responses holding key pairs are never stored.
+
+Writes made through the CLI invalidate the cached responses of the resource
+they change. Use --no-cache to always go to the server.`,
+	}
+
+	cmd.AddCommand(newCacheClearCmd())
+
+	return cmd
+}
+
+func newCacheClearCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "clear",
+		Short: "Remove all cached responses and completion results",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := api.NewResponseCache(filepath.Join(config.Dir(), httpCacheDir), 0).Clear(); err != nil {
+				return fmt.Errorf("clear response cache: %w", err)
+			}
+			if err := completionCache().Clear(); err != nil {
+				return fmt.Errorf("clear completion cache: %w", err)
+			}
+
+			_, _ = fmt.Fprintln(cmd.OutOrStdout(), "Cache cleared")
+			return nil
+		},
+	}
+}
+
+// responseCache returns the cache selected by --no-cache and --cache-ttl.
+// With --no-cache every request goes to the server, but responses are still
+// stored so later commands benefit from them. Giving --cache-ttl opts in to
+// caching responses the server did not say could be cached.
+func responseCache() *api.ResponseCache {
+	ttl := cacheTTL.ttl
+	if noCache {
+		ttl = 0
+	}
+	var opts []api.CacheOption
+	if cacheTTL.set {
+		opts = append(opts, api.WithAssumedFreshness())
+	}
+	return api.NewResponseCache(filepath.Join(config.Dir(), httpCacheDir), ttl, opts...)
+}
+
+// cacheTTLValue is the --cache-ttl flag, remembering whether it was given.
+type cacheTTLValue struct {
+	ttl time.Duration
+	set bool
+}
+
+func (v *cacheTTLValue) Set(s string) error {
+	ttl, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	v.ttl, v.set = ttl, true
+	return nil
+}
+
+func (v *cacheTTLValue) String() string {
+	return v.ttl.String()
+}
+
+func (v *cacheTTLValue) Type() string {
+	return "duration"
+}
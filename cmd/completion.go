@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	"github.com/EnSync-engine/CLI/app/api"
+	"github.com/EnSync-engine/CLI/app/completion"
+	"github.com/EnSync-engine/CLI/app/config"
+)
+
+const (
+	envAccessKey = "ENSYNC_ACCESS_KEY"
+
+	completionCacheDir = "cache/completion"
+	completionCacheTTL = 60 * time.Second
+	completionTimeout  = 5 * time.Second
+)
+
+func newCompletionCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "completion [bash|zsh|fish|powershell]",
+		Short: "Generate shell completion scripts",
+		Long: `Generate a completion script for your shell.
+
+Besides commands and flags, event names, access key IDs and workspace
+paths are completed by querying the server. Access keys themselves are
+never completed, as they are credentials. Completion uses --access-key
+when it is already on the command line, otherwise the profile's auth
+settings or ENSYNC_ACCESS_KEY. Profiles authenticating with key_command or
+OAuth2 get no resource completion, so completing never runs a command or
+waits for a token.
+Results are cached under ~/.ensync/cache/completion for a minute.
+
+Bash:
+  source <(ensync completion bash)
+  # or permanently:
+  ensync completion bash > /etc/bash_completion.d/ensync
+
+Zsh:
+  ensync completion zsh > "${fpath[1]}/_ensync"
+
+Fish:
+  ensync completion fish > ~/.config/fish/completions/ensync.fish
+
+PowerShell:
+  ensync completion powershell | Out-String | Invoke-Expression`,
+		Args:      cobra.ExactArgs(1),
+		ValidArgs: []string{"bash", "zsh", "fish", "powershell"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			root := cmd.Root()
+			out := cmd.OutOrStdout()
+
+			switch args[0] {
+			case "bash":
+				return root.GenBashCompletionV2(out, true)
+			case "zsh":
+				return root.GenZshCompletion(out)
+			case "fish":
+				return root.GenFishCompletion(out, true)
+			case "powershell":
+				return root.GenPowerShellCompletionWithDesc(out)
+			default:
+				return fmt.Errorf("unsupported shell %q: use bash, zsh, fish or powershell", args[0])
+			}
+		},
+	}
+
+	return cmd
+}
+
+// completeResource returns a ValidArgsFunction completing the first
+// positional argument with live resource names.
+func completeResource(client *api.Client, kind completion.Kind) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return completeResourceNames(cmd, client, kind, toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeResourceFlag is completeResource for flag values.
+func completeResourceFlag(client *api.Client, kind completion.Kind) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return completeResourceNames(cmd, client, kind, toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+func completeResourceNames(cmd *cobra.Command, client *api.Client, kind completion.Kind, toComplete string) []string {
+	// Completion runs without the usual PreRun hooks, so the client is
+	// configured here. Errors simply mean no suggestions.
+	accessKey, _ := cmd.Flags().GetString("access-key")
+	cfg, err := setupClient(client)
+	if err != nil {
+		return nil
+	}
+	// Anything logged or written to stderr would end up in the candidates
+	// or the user's prompt.
+	client.Configure(api.WithLogger(zap.NewNop()), api.WithThrottleHandler(nil))
+	auth, err := authenticate(client, accessKey)
+	if err != nil || !completion.CanAuthenticate(auth) {
+		return nil
+	}
+	if kind != completion.Workspaces {
+		client.SetWorkspace(workspaceScope(cfg))
+	}
+
+	ctx, cancel := context.WithTimeout(cmd.Context(), completionTimeout)
+	defer cancel()
+
+	names, err := completionCache().Names(ctx, client, kind, completionCacheKey(cfg, auth, kind))
+	if err != nil {
+		return nil
+	}
+
+	var matches []string
+	for _, name := range names {
+		if strings.HasPrefix(name, toComplete) {
+			matches = append(matches, name)
+		}
+	}
+	return matches
+}
+
+// completionCacheKey keys cached names by server, credentials, signing key,
+// workspace scope and resource kind.
+func completionCacheKey(cfg *config.Config, auth api.Authenticator, kind completion.Kind) string {
+	// Static keys are told apart by the key itself; other authenticators by
+	// where they get their credentials from.
+	credentials := fmt.Sprint(auth)
+	if key, ok := auth.(api.StaticKey); ok {
+		credentials = string(key)
+	}
+	return completion.Key(cfg.BaseURL, credentials, cfg.Signing.KeyPairFile, workspaceScope(cfg), string(kind))
+}
+
+func completionCache() *completion.Cache {
+	return completion.NewCache(filepath.Join(config.Dir(), completionCacheDir), completionCacheTTL)
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	"github.com/EnSync-engine/CLI/app/api"
+	"github.com/EnSync-engine/CLI/app/doctor"
+	"github.com/EnSync-engine/CLI/pkg/version"
+)
+
+const doctorTimeout = 10 * time.Second
+
+func newDoctorCmd(client *api.Client) *cobra.Command {
+	var (
+		accessKey  string
+		jsonOutput bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check configuration and connectivity",
+		Long: `Run a checklist of common setup problems: the config file, whether the
+server is reachable and its TLS certificate, clock skew, proxy settings,
+server version compatibility, the credentials and the access key's
+permissions, the remaining rate limit and the circuit breaker.
+
+Credentials are taken from --access-key, the profile's auth settings or
+ENSYNC_ACCESS_KEY; without any the access key check is skipped. Exits with
+an error if any check fails.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := context.WithTimeout(cmd.Context(), doctorTimeout)
+			defer cancel()
+			results := runDoctor(ctx, client, accessKey)
+
+			if jsonOutput {
+				if err := printJSON(cmd.OutOrStdout(), results); err != nil {
+					return err
+				}
+			} else {
+				printDoctorResults(cmd.OutOrStdout(), results)
+			}
+
+			if failed := doctor.Failed(results); failed > 0 {
+				return fmt.Errorf("%d of %d checks failed", failed, len(results))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&accessKey, "access-key", "", "access key to check (default from the profile's auth settings or $"+envAccessKey+")")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "output as JSON")
+
+	return cmd
+}
+
+// runDoctor runs the checks in order. Without a usable configuration there
+// is nothing else to check.
+func runDoctor(ctx context.Context, client *api.Client, accessKey string) []doctor.Result {
+	cfg, err := setupClient(client)
+	results := []doctor.Result{doctor.CheckConfig(cfg, err)}
+	if err != nil {
+		return results
+	}
+	// Failures are reported as check results; request logs would only
+	// repeat them, and break --json.
+	if !debug && !cfg.Debug {
+		client.Configure(api.WithLogger(zap.NewNop()))
+	}
+	auth, authErr := authenticate(client, accessKey)
+	client.SetWorkspace(workspaceScope(cfg))
+	// Every check must reflect the server as it is now.
+	ctx = api.WithoutCache(ctx)
+
+	probe, probeErr := client.Probe(ctx)
+	results = append(results,
+		doctor.CheckReachability(cfg.BaseURL, probe, probeErr),
+		doctor.CheckTLS(cfg.BaseURL, probe, probeErr),
+		doctor.CheckClockSkew(probe, probeErr, time.Now()),
+		doctor.CheckProxy(probe, probeErr),
+		doctor.CheckServerVersion(probe, probeErr, version.Get().Version),
+	)
+	// Only access keys can be looked up; other credentials are checked by
+	// using them.
+	switch key, isKey := auth.(api.StaticKey); {
+	case authErr != nil:
+		results = append(results, doctor.CheckAccessKey(ctx, client, ""))
+	case isKey:
+		results = append(results, doctor.CheckAccessKey(ctx, client, string(key)))
+	default:
+		results = append(results, doctor.CheckAuthentication(ctx, client, auth))
+	}
+
+	// The credentials check is the request that reports the rate limit and
+	// feeds the circuit breaker.
+	quota, ok := client.RateLimitQuota()
+	breakerEnabled := cfg.CircuitBreaker.Enabled == nil || *cfg.CircuitBreaker.Enabled
+	results = append(results,
+		doctor.CheckRateLimit(quota, ok),
+		doctor.CheckCircuits(client.CircuitStates(), breakerEnabled),
+	)
+	return results
+}
+
+func printDoctorResults(w io.Writer, results []doctor.Result) {
+	width := 0
+	for _, result := range results {
+		width = max(width, len(result.Name))
+	}
+
+	for _, result := range results {
+		_, _ = fmt.Fprintf(w, "[%s] %-*s  %s\n", strings.ToUpper(string(result.Status)), width, result.Name, result.Detail)
+		if result.Hint != "" {
+			_, _ = fmt.Fprintf(w, "       %*s  hint: %s\n", width, "", result.Hint)
+		}
+	}
+}
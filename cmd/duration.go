@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const day = 24 * time.Hour
+
+// parseDuration parses a Go duration ("36h") or a whole number of days or
+// weeks ("90d", "2w"), the units key lifetimes are usually given in.
+func parseDuration(s string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{"d": day, "w": 7 * day} {
+		if number, ok := strings.CutSuffix(s, suffix); ok {
+			n, err := strconv.Atoi(number)
+			if err != nil || n < 0 {
+				return 0, fmt.Errorf("invalid duration %q", s)
+			}
+			return time.Duration(n) * unit, nil
+		}
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid duration %q: use e.g. 90d, 2w or 36h", s)
+	}
+	return d, nil
+}
+
+// durationValue is a flag value parsed with parseDuration.
+type durationValue time.Duration
+
+func (d *durationValue) Set(s string) error {
+	parsed, err := parseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = durationValue(parsed)
+	return nil
+}
+
+func (d *durationValue) String() string {
+	if *d == 0 {
+		return ""
+	}
+	duration := time.Duration(*d)
+	if duration%day == 0 {
+		return fmt.Sprintf("%dd", duration/day)
+	}
+	return duration.String()
+}
+
+func (d *durationValue) Type() string {
+	return "duration"
+}
+
+// parseTime parses an RFC 3339 time or a date, which is taken as midnight
+// UTC.
+func parseTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q: use RFC 3339 (2025-01-31T12:00:00Z) or a date (2025-01-31)", s)
+	}
+	return t, nil
+}
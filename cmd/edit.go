@@ -0,0 +1,388 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/EnSync-engine/CLI/app/api"
+	"github.com/EnSync-engine/CLI/app/completion"
+	"github.com/EnSync-engine/CLI/app/domain"
+	"github.com/EnSync-engine/CLI/pkg/editor"
+)
+
+const editErrorPrefix = "# ERROR: "
+
+// errEditCancelled is returned by an edit session when the user saved an
+// unchanged or empty document.
+var errEditCancelled = errors.New("edit cancelled, no changes made")
+
+// editableEvent is the YAML document shown for `edit event`.
+type editableEvent struct {
+	Name    string         `yaml:"name"`
+	Payload map[string]any `yaml:"payload"`
+}
+
+// editableAccessKey is the YAML document shown for `edit access-key`.
+type editableAccessKey struct {
+	Permissions editablePermissions `yaml:"permissions"`
+}
+
+type editablePermissions struct {
+	Send    []string `yaml:"send"`
+	Receive []string `yaml:"receive"`
+}
+
+func newEditCmd(client *api.Client) *cobra.Command {
+	var (
+		accessKey string
+		yes       bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "edit",
+		Short: "Edit resources in $EDITOR",
+		Long: `Open a resource as YAML in $VISUAL or $EDITOR, validate the result,
+show a diff and send only the changes.
+
+If the edited document is invalid, the editor is reopened with the errors
+added as comments at the top. Save an empty or unchanged document to cancel.
+
+To remove a payload key, delete it from the document. Null values are
+rejected, since the changes are sent as a merge patch in which null means
+removal.`,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := setupClient(client)
+			if err != nil {
+				return err
+			}
+			if _, err := authenticate(client, accessKey); err != nil {
+				return err
+			}
+			client.SetWorkspace(workspaceScope(cfg))
+			return nil
+		},
+	}
+
+	cmd.PersistentFlags().StringVar(&accessKey, "access-key", "", accessKeyUsage)
+	cmd.PersistentFlags().BoolVarP(&yes, "yes", "y", false, "apply the changes without asking for confirmation")
+
+	cmd.AddCommand(
+		newEditEventCmd(client, &yes),
+		newEditAccessKeyCmd(client, &yes),
+	)
+
+	return cmd
+}
+
+func newEditEventCmd(client *api.Client, yes *bool) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "event [name]",
+		Short:             "Edit an event's name and payload",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeResource(client, completion.Events),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			event, err := client.GetEventByName(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+
+			original := editableEvent{Name: event.Name, Payload: event.Payload}
+			header := fmt.Sprintf("Editing event %q (id: %s)", event.Name, event.ID)
+
+			var edited editableEvent
+			err = editDocument(cmd, header, original, &edited, validateEditedEvent)
+			if errors.Is(err, errEditCancelled) {
+				_, _ = fmt.Fprintln(cmd.OutOrStdout(), err.Error())
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+
+			after := &domain.Event{Name: edited.Name, Payload: edited.Payload}
+			patch := domain.NewEventPatch(event, after)
+			if patch.IsEmpty() {
+				_, _ = fmt.Fprintln(cmd.OutOrStdout(), errEditCancelled.Error())
+				return nil
+			}
+
+			if ok, err := confirmApply(cmd, *yes); err != nil || !ok {
+				return err
+			}
+
+			if err := client.PatchEvent(cmd.Context(), event.ID, patch); err != nil {
+				return err
+			}
+
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Event %q updated successfully\n", edited.Name)
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func newEditAccessKeyCmd(client *api.Client, yes *bool) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "access-key [id]",
+		Short:             "Edit an access key's permissions",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeResource(client, completion.AccessKeyIDs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			key, err := client.GetAccessKeyByID(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+
+			original := editableAccessKey{}
+			if key.Permissions != nil {
+				original.Permissions = editablePermissions{Send: key.Permissions.Send, Receive: key.Permissions.Receive}
+			}
+			header := fmt.Sprintf("Editing permissions of access key %q (id: %s)", key.Name, key.ID)
+
+			var edited editableAccessKey
+			err = editDocument(cmd, header, original, &edited, validateEditedAccessKey)
+			if errors.Is(err, errEditCancelled) {
+				_, _ = fmt.Fprintln(cmd.OutOrStdout(), err.Error())
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+
+			if ok, err := confirmApply(cmd, *yes); err != nil || !ok {
+				return err
+			}
+
+			permissions := &domain.Permissions{Send: edited.Permissions.Send, Receive: edited.Permissions.Receive}
+			if err := client.SetAccessKeyPermissions(cmd.Context(), key.Key, permissions); err != nil {
+				return err
+			}
+
+			_, _ = fmt.Fprintln(cmd.OutOrStdout(), "Permissions updated successfully")
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// editDocument opens original as YAML in the editor and decodes the result
+// into edited, reopening the editor with the validation errors until the
+// document is valid. It prints a diff of the accepted changes and returns
+// errEditCancelled when there are none.
+func editDocument[T any](cmd *cobra.Command, header string, original T, edited *T, validate func(*T) []string) error {
+	originalYAML, err := marshalYAML(original)
+	if err != nil {
+		return fmt.Errorf("encode document: %w", err)
+	}
+
+	content := append([]byte(editHeader(header)), originalYAML...)
+	for {
+		result, err := editor.Edit(content, "ensync-edit-*.yaml")
+		if err != nil {
+			return err
+		}
+
+		body := stripEditErrors(result)
+		if len(bytes.TrimSpace(stripComments(body))) == 0 {
+			return errEditCancelled
+		}
+
+		problems := decodeEdited(body, edited)
+		if len(problems) == 0 {
+			problems = validate(edited)
+		}
+		if len(problems) > 0 {
+			content = append([]byte(editErrorComments(problems)), body...)
+			continue
+		}
+
+		editedYAML, err := marshalYAML(edited)
+		if err != nil {
+			return fmt.Errorf("encode document: %w", err)
+		}
+		if bytes.Equal(originalYAML, editedYAML) {
+			return errEditCancelled
+		}
+
+		_, _ = fmt.Fprint(cmd.OutOrStdout(), lineDiff(string(originalYAML), string(editedYAML)))
+		return nil
+	}
+}
+
+func marshalYAML(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeEdited[T any](body []byte, target *T) []string {
+	var zero T
+	*target = zero
+
+	decoder := yaml.NewDecoder(bytes.NewReader(body))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(target); err != nil {
+		return []string{fmt.Sprintf("invalid YAML: %v", err)}
+	}
+	return nil
+}
+
+func validateEditedEvent(event *editableEvent) []string {
+	var problems []string
+	if strings.TrimSpace(event.Name) == "" {
+		problems = append(problems, "name must not be empty")
+	}
+
+	// Round-trip the payload through JSON so it is sent exactly as the
+	// server will store it, and so YAML-only constructs are rejected.
+	data, err := json.Marshal(event.Payload)
+	if err != nil {
+		return append(problems, fmt.Sprintf("payload is not valid JSON: %v", err))
+	}
+	var payload map[string]any
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return append(problems, fmt.Sprintf("payload is not valid JSON: %v", err))
+	}
+	event.Payload = payload
+
+	// Changes are sent as a merge patch, in which null removes a key, so a
+	// null value could only ever be sent as a removal.
+	for _, path := range nullPaths("payload", payload) {
+		problems = append(problems, fmt.Sprintf("%s is null: delete the key to remove it", path))
+	}
+
+	return problems
+}
+
+// nullPaths returns the dotted paths of the null values in object, sorted.
+func nullPaths(prefix string, object map[string]any) []string {
+	var paths []string
+	for key, value := range object {
+		switch value := value.(type) {
+		case nil:
+			paths = append(paths, prefix+"."+key)
+		case map[string]any:
+			paths = append(paths, nullPaths(prefix+"."+key, value)...)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func validateEditedAccessKey(key *editableAccessKey) []string {
+	var problems []string
+	check := func(field string, channels []string) {
+		for i, channel := range channels {
+			if strings.TrimSpace(channel) == "" {
+				problems = append(problems, fmt.Sprintf("permissions.%s[%d] must not be empty", field, i))
+			}
+		}
+	}
+	check("send", key.Permissions.Send)
+	check("receive", key.Permissions.Receive)
+	return problems
+}
+
+func confirmApply(cmd *cobra.Command, yes bool) (bool, error) {
+	if yes {
+		return true, nil
+	}
+	ok, err := confirm(cmd, "Apply these changes?")
+	if err == nil && !ok {
+		_, _ = fmt.Fprintln(cmd.OutOrStdout(), "Aborted")
+	}
+	return ok, err
+}
+
+func editHeader(title string) string {
+	return "# " + title + `
+# Lines starting with '#' are ignored. Save an empty or unchanged
+# document to cancel.
+`
+}
+
+func editErrorComments(problems []string) string {
+	var b strings.Builder
+	for _, problem := range problems {
+		b.WriteString(editErrorPrefix + problem + "\n")
+	}
+	b.WriteString("#\n")
+	return b.String()
+}
+
+// stripEditErrors removes error comments added by a previous attempt.
+func stripEditErrors(content []byte) []byte {
+	lines := strings.Split(string(content), "\n")
+	start := 0
+	for start < len(lines) && (strings.HasPrefix(lines[start], editErrorPrefix) || lines[start] == "#") {
+		start++
+	}
+	return []byte(strings.Join(lines[start:], "\n"))
+}
+
+func stripComments(content []byte) []byte {
+	var kept []string
+	for _, line := range strings.Split(string(content), "\n") {
+		if !strings.HasPrefix(strings.TrimSpace(line), "#") {
+			kept = append(kept, line)
+		}
+	}
+	return []byte(strings.Join(kept, "\n"))
+}
+
+// lineDiff returns a minimal line-based diff of a and b, prefixing removed
+// lines with "-", added lines with "+" and unchanged lines with " ".
+func lineDiff(a, b string) string {
+	before := strings.Split(strings.TrimSuffix(a, "\n"), "\n")
+	after := strings.Split(strings.TrimSuffix(b, "\n"), "\n")
+
+	// lcs[i][j] is the length of the longest common subsequence of
+	// before[i:] and after[j:].
+	lcs := make([][]int, len(before)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(after)+1)
+	}
+	for i := len(before) - 1; i >= 0; i-- {
+		for j := len(after) - 1; j >= 0; j-- {
+			if before[i] == after[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var out strings.Builder
+	i, j := 0, 0
+	for i < len(before) || j < len(after) {
+		switch {
+		case i < len(before) && j < len(after) && before[i] == after[j]:
+			out.WriteString("  " + before[i] + "\n")
+			i++
+			j++
+		case i < len(before) && (j == len(after) || lcs[i+1][j] >= lcs[i][j+1]):
+			out.WriteString("- " + before[i] + "\n")
+			i++
+		default:
+			out.WriteString("+ " + after[j] + "\n")
+			j++
+		}
+	}
+	return out.String()
+}
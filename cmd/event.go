@@ -1,12 +1,16 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/spf13/cobra"
 
 	"github.com/EnSync-engine/CLI/app/api"
+	"github.com/EnSync-engine/CLI/app/bulk"
+	"github.com/EnSync-engine/CLI/app/completion"
 	"github.com/EnSync-engine/CLI/app/domain"
 )
 
@@ -16,24 +20,29 @@ func newEventCmd(client *api.Client) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "event",
 		Short: "Manage events",
-		Long:  "Commands for listing, creating, updating, and retrieving events.",
+		Long:  "Commands for listing, creating, updating, renaming, deleting, and retrieving events.",
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-			if accessKey == "" {
-				return fmt.Errorf("--access-key is required")
+			cfg, err := setupClient(client)
+			if err != nil {
+				return err
+			}
+			if _, err := authenticate(client, accessKey); err != nil {
+				return err
 			}
-			client.SetAccessKey(accessKey)
+			client.SetWorkspace(workspaceScope(cfg))
 			return nil
 		},
 	}
 
-	cmd.PersistentFlags().StringVar(&accessKey, "access-key", "", "access key for API authentication (required)")
-	_ = cmd.MarkPersistentFlagRequired("access-key")
+	cmd.PersistentFlags().StringVar(&accessKey, "access-key", "", accessKeyUsage)
 
 	cmd.AddCommand(
 		newEventListCmd(client),
 		newEventGetCmd(client),
 		newEventCreateCmd(client),
 		newEventUpdateCmd(client),
+		newEventDeleteCmd(client),
+		newEventRenameCmd(client),
 	)
 
 	return cmd
@@ -77,9 +86,10 @@ func newEventListCmd(client *api.Client) *cobra.Command {
 
 func newEventGetCmd(client *api.Client) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "get [name]",
-		Short: "Get event by name",
-		Args:  cobra.ExactArgs(1),
+		Use:               "get [name]",
+		Short:             "Get event by name",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeResource(client, completion.Events),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			event, err := client.GetEventByName(cmd.Context(), args[0])
 			if err != nil {
@@ -96,12 +106,21 @@ func newEventCreateCmd(client *api.Client) *cobra.Command {
 	var (
 		name        string
 		payloadJSON string
+		bulkOpts    bulkOptions
 	)
 
 	cmd := &cobra.Command{
 		Use:   "create",
 		Short: "Create a new event",
+		Long: `Create a new event, or one event per row with --from-file.
+
+NDJSON rows are objects with "name" and "payload" fields. CSV files have a
+header row with "name" and "payload" columns, the payload given as JSON.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if bulkOpts.fromFile != "" {
+				return runBulk(cmd, client, &bulkOpts, jobKindCreateEvents, nil)
+			}
+
 			payload, err := parsePayloadJSON(payloadJSON)
 			if err != nil {
 				return err
@@ -121,13 +140,52 @@ func newEventCreateCmd(client *api.Client) *cobra.Command {
 		},
 	}
 
-	cmd.Flags().StringVar(&name, "name", "", "event name (required)")
+	cmd.Flags().StringVar(&name, "name", "", "event name (required unless --from-file is set)")
 	cmd.Flags().StringVar(&payloadJSON, "payload", "{}", "event payload as JSON")
-	_ = cmd.MarkFlagRequired("name")
+	addBulkFlags(cmd, &bulkOpts)
+	cmd.MarkFlagsOneRequired("name", "from-file")
+	cmd.MarkFlagsMutuallyExclusive("name", "from-file")
+	cmd.MarkFlagsMutuallyExclusive("payload", "from-file")
 
 	return cmd
 }
 
+// createEventTask creates the event described by each --from-file row.
+func createEventTask(client *api.Client, _ map[string]string) bulk.Task {
+	return func(ctx context.Context, record bulk.Record) (string, any, error) {
+		event, err := eventFromRecord(record)
+		if err != nil {
+			return event.Name, nil, err
+		}
+		return event.Name, nil, client.CreateEvent(ctx, event)
+	}
+}
+
+// eventFromRecord converts a --from-file row into an event.
+func eventFromRecord(record bulk.Record) (*domain.Event, error) {
+	event := &domain.Event{}
+
+	name, err := record.String("name")
+	if err != nil {
+		return event, err
+	}
+	if name == "" {
+		return event, fmt.Errorf("name is required")
+	}
+	event.Name = name
+
+	payload, err := record.Object("payload")
+	if err != nil {
+		return event, err
+	}
+	if payload == nil {
+		payload = map[string]any{}
+	}
+	event.Payload = payload
+
+	return event, nil
+}
+
 func newEventUpdateCmd(client *api.Client) *cobra.Command {
 	var (
 		id          string
@@ -138,19 +196,29 @@ func newEventUpdateCmd(client *api.Client) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "update",
 		Short: "Update an existing event",
+		Long: `Update an existing event, sending only the fields that were set.
+
+The payload is applied as a JSON Merge Patch: keys present in --payload
+replace existing keys, keys set to null are removed, and all other keys
+are left untouched.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			payload, err := parsePayloadJSON(payloadJSON)
-			if err != nil {
-				return err
+			patch := &domain.EventPatch{}
+			if cmd.Flags().Changed("name") {
+				patch.Name = &name
+			}
+			if cmd.Flags().Changed("payload") {
+				payload, err := parsePayloadJSON(payloadJSON)
+				if err != nil {
+					return err
+				}
+				patch.Payload = payload
 			}
 
-			event := &domain.Event{
-				ID:      id,
-				Name:    name,
-				Payload: payload,
+			if patch.IsEmpty() {
+				return fmt.Errorf("nothing to update: set --name and/or --payload")
 			}
 
-			if err := client.UpdateEvent(cmd.Context(), event); err != nil {
+			if err := client.PatchEvent(cmd.Context(), id, patch); err != nil {
 				return err
 			}
 
@@ -161,12 +229,175 @@ func newEventUpdateCmd(client *api.Client) *cobra.Command {
 
 	cmd.Flags().StringVar(&id, "id", "", "event ID (required)")
 	cmd.Flags().StringVar(&name, "name", "", "new event name")
-	cmd.Flags().StringVar(&payloadJSON, "payload", "{}", "event payload as JSON")
+	cmd.Flags().StringVar(&payloadJSON, "payload", "", "payload changes as JSON (merged into the existing payload)")
 	_ = cmd.MarkFlagRequired("id")
 
 	return cmd
 }
 
+func newEventDeleteCmd(client *api.Client) *cobra.Command {
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:               "delete [name|id]",
+		Short:             "Delete an event by name or ID",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeResource(client, completion.Events),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			event, err := resolveEvent(cmd.Context(), client, args[0])
+			if err != nil {
+				return err
+			}
+
+			if !force {
+				ok, err := confirm(cmd, fmt.Sprintf("Delete event %q (id=%s)?", event.Name, event.ID))
+				if err != nil {
+					return err
+				}
+				if !ok {
+					_, _ = fmt.Fprintln(cmd.OutOrStdout(), "Aborted")
+					return nil
+				}
+			}
+
+			if err := client.DeleteEvent(cmd.Context(), event.ID); err != nil {
+				return err
+			}
+
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Event %q deleted successfully\n", event.Name)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&force, "force", "f", false, "delete without asking for confirmation")
+
+	return cmd
+}
+
+func newEventRenameCmd(client *api.Client) *cobra.Command {
+	var rewritePermissions bool
+
+	cmd := &cobra.Command{
+		Use:   "rename [old-name] [new-name]",
+		Short: "Rename an event",
+		Long: `Rename an event.
+
+Access keys whose send or receive permissions list the old name are
+reported. With --rewrite-permissions those keys are updated to the new
+name without losing access at any point: the new name is first added next
+to the old one on every key, then the event is renamed, then the old name
+is removed. If adding the new name or renaming the event fails, every key
+is restored and the event keeps its name.`,
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeResource(client, completion.Events),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			oldName, newName := args[0], args[1]
+
+			event, err := client.GetEventByName(cmd.Context(), oldName)
+			if err != nil {
+				return err
+			}
+
+			keys, err := listAllAccessKeys(cmd.Context(), client)
+			if err != nil {
+				return err
+			}
+			affected := keysReferencingEvent(keys, oldName)
+
+			if !rewritePermissions || len(affected) == 0 {
+				if err := client.PatchEvent(cmd.Context(), event.ID, &domain.EventPatch{Name: &newName}); err != nil {
+					return err
+				}
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Event %q renamed to %q\n", oldName, newName)
+				if len(affected) > 0 {
+					_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Warning: %d access key(s) still reference %q:\n", len(affected), oldName)
+					for _, key := range affected {
+						_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "  - %s (%s)\n", key.Name, key.ID)
+					}
+					_, _ = fmt.Fprintln(cmd.ErrOrStderr(), "Re-run with --rewrite-permissions to update them.")
+				}
+				return nil
+			}
+
+			if err := renameWithPermissions(cmd.Context(), client, event, affected, newName); err != nil {
+				return err
+			}
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Event %q renamed to %q; updated permissions on %d access key(s)\n", oldName, newName, len(affected))
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&rewritePermissions, "rewrite-permissions", false, "update access key permissions that reference the old name")
+
+	return cmd
+}
+
+// resolveEvent looks up an event by name, falling back to its ID.
+func resolveEvent(ctx context.Context, client api.EventService, nameOrID string) (*domain.Event, error) {
+	event, err := client.GetEventByName(ctx, nameOrID)
+	if err == nil {
+		return event, nil
+	}
+	if !api.IsNotFound(err) {
+		return nil, err
+	}
+	return client.GetEventByID(ctx, nameOrID)
+}
+
+func keysReferencingEvent(keys []*domain.AccessKeyPermissions, eventName string) []*domain.AccessKeyPermissions {
+	var affected []*domain.AccessKeyPermissions
+	for _, key := range keys {
+		if key.Permissions != nil && key.Permissions.References(eventName) {
+			affected = append(affected, key)
+		}
+	}
+	return affected
+}
+
+// renameWithPermissions renames the event and the references to it in the
+// keys' permissions so that no key loses access at any point. It adds the
+// new name to every key, renames the event, then drops the old name. Until
+// the event is renamed, any failure restores the keys; rollbacks run even
+// if ctx is cancelled.
+func renameWithPermissions(ctx context.Context, client api.APIClient, event *domain.Event, keys []*domain.AccessKeyPermissions, newName string) error {
+	oldName := event.Name
+	rollbackCtx := context.WithoutCancel(ctx)
+
+	var expanded []*domain.AccessKeyPermissions
+	restore := func(err error) error {
+		for _, key := range expanded {
+			if rbErr := client.SetAccessKeyPermissions(rollbackCtx, key.Key, key.Permissions); rbErr != nil {
+				return fmt.Errorf("%w; restoring permissions of %s also failed: %v", err, key.ID, rbErr)
+			}
+		}
+		return fmt.Errorf("%w; access keys restored and event not renamed", err)
+	}
+
+	for _, key := range keys {
+		if err := client.SetAccessKeyPermissions(ctx, key.Key, key.Permissions.AddEventAlias(oldName, newName)); err != nil {
+			return restore(fmt.Errorf("add %q to permissions of %s: %w", newName, key.ID, err))
+		}
+		expanded = append(expanded, key)
+	}
+
+	if err := client.PatchEvent(ctx, event.ID, &domain.EventPatch{Name: &newName}); err != nil {
+		return restore(err)
+	}
+
+	// The rename has happened and every key lists the new name, so a key
+	// still listing the old one as well keeps working.
+	var stale []string
+	for _, key := range keys {
+		if err := client.SetAccessKeyPermissions(rollbackCtx, key.Key, key.Permissions.RenameEvent(oldName, newName)); err != nil {
+			stale = append(stale, key.ID)
+		}
+	}
+	if len(stale) > 0 {
+		return fmt.Errorf("event renamed, but %q could not be removed from the permissions of %s", oldName, strings.Join(stale, ", "))
+	}
+	return nil
+}
+
 func parsePayloadJSON(s string) (map[string]any, error) {
 	var payload map[string]any
 	if err := json.Unmarshal([]byte(s), &payload); err != nil {
@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/EnSync-engine/CLI/app/api"
+	"github.com/EnSync-engine/CLI/app/bulk"
+	"github.com/EnSync-engine/CLI/app/jobs"
+)
+
+// jobSummary is a job with its progress, as shown by `jobs list` and `jobs show`.
+type jobSummary struct {
+	*jobs.Job
+	Succeeded int `json:"succeeded"`
+	Failed    int `json:"failed"`
+	Pending   int `json:"pending"`
+}
+
+func newJobsCmd(client *api.Client) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "jobs",
+		Short: "Inspect and resume batch jobs",
+		Long: `Batch commands such as "event create --from-file" run as jobs. Each job
+keeps a checkpoint journal under ~/.ensync/jobs/<id>, so a job stopped by
+an error, a network failure or Ctrl-C can be resumed where it left off.
+
+Rows are sent with an idempotency key derived from the job ID and row, so a
+row that was in flight when the job stopped is not created twice.`,
+	}
+
+	cmd.AddCommand(
+		newJobsListCmd(),
+		newJobsShowCmd(),
+		newJobsCancelCmd(),
+		newJobsResumeCmd(client),
+	)
+
+	return cmd
+}
+
+func newJobsListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List batch jobs, most recent first",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store := jobStore()
+			list, err := store.List()
+			if err != nil {
+				return err
+			}
+
+			summaries := make([]*jobSummary, 0, len(list))
+			for _, job := range list {
+				summary, err := summarizeJob(store, job)
+				if err != nil {
+					return err
+				}
+				summaries = append(summaries, summary)
+			}
+
+			return printJSON(cmd.OutOrStdout(), summaries)
+		},
+	}
+}
+
+func newJobsShowCmd() *cobra.Command {
+	var failures bool
+
+	cmd := &cobra.Command{
+		Use:               "show [id]",
+		Short:             "Show a batch job's progress",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeJobIDs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store := jobStore()
+			job, err := store.Get(args[0])
+			if err != nil {
+				return err
+			}
+
+			if failures {
+				results, err := store.Results(job)
+				if err != nil {
+					return err
+				}
+				var failed []bulk.Result
+				for _, result := range results {
+					if result.Status == bulk.StatusFailed {
+						failed = append(failed, result)
+					}
+				}
+				return printJSON(cmd.OutOrStdout(), failed)
+			}
+
+			summary, err := summarizeJob(store, job)
+			if err != nil {
+				return err
+			}
+			return printJSON(cmd.OutOrStdout(), summary)
+		},
+	}
+
+	cmd.Flags().BoolVar(&failures, "failures", false, "show the failed rows and their errors")
+
+	return cmd
+}
+
+func newJobsCancelCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:               "cancel [id]",
+		Short:             "Cancel a batch job so it stops and cannot be resumed",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeJobIDs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			job, err := jobStore().Cancel(args[0])
+			if err != nil {
+				return err
+			}
+
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Job %s cancelled\n", job.ID)
+			return nil
+		},
+	}
+}
+
+func newJobsResumeCmd(client *api.Client) *cobra.Command {
+	var (
+		accessKey       string
+		workers         int
+		continueOnError bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "resume [id]",
+		Short: "Resume a batch job, processing the rows that have not succeeded",
+		Long: `Resume a batch job. Rows that failed or were never processed are run
+again; rows that already succeeded are skipped. The job runs against the
+same server and workspace it was started with.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeJobIDs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store := jobStore()
+			job, err := store.Get(args[0])
+			if err != nil {
+				return err
+			}
+			if !job.Resumable() {
+				return fmt.Errorf("job %s is %s and cannot be resumed", job.ID, job.Status)
+			}
+
+			cfg, err := setupClient(client)
+			if err != nil {
+				return err
+			}
+			if job.BaseURL != "" && strings.TrimSuffix(job.BaseURL, "/") != strings.TrimSuffix(cfg.BaseURL, "/") {
+				return fmt.Errorf("job %s was started against %s, but the current base URL is %s: select the matching --profile", job.ID, job.BaseURL, cfg.BaseURL)
+			}
+			if _, err := authenticate(client, accessKey); err != nil {
+				return err
+			}
+			client.SetWorkspace(job.Workspace)
+
+			if cmd.Flags().Changed("workers") {
+				job.Workers = workers
+			}
+			if cmd.Flags().Changed("continue-on-error") {
+				job.ContinueOnError = continueOnError
+			}
+			job.Status = jobs.StatusRunning
+			if err := store.Save(job); err != nil {
+				return err
+			}
+
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Resuming job %s\n", job.ID)
+			return runJob(cmd, client, store, job)
+		},
+	}
+
+	cmd.Flags().StringVar(&accessKey, "access-key", "", accessKeyUsage)
+	cmd.Flags().IntVar(&workers, "workers", defaultBulkWorkers, "number of rows processed concurrently (default from the job)")
+	cmd.Flags().BoolVar(&continueOnError, "continue-on-error", false, "keep going after a row fails (default from the job)")
+
+	return cmd
+}
+
+func summarizeJob(store *jobs.Store, job *jobs.Job) (*jobSummary, error) {
+	results, err := store.Results(job)
+	if err != nil {
+		return nil, err
+	}
+	return &jobSummary{
+		Job:       job,
+		Succeeded: bulk.Count(results, bulk.StatusSucceeded),
+		Failed:    bulk.Count(results, bulk.StatusFailed),
+		Pending:   bulk.Count(results, bulk.StatusSkipped),
+	}, nil
+}
+
+func completeJobIDs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	list, err := jobStore().List()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var ids []string
+	for _, job := range list {
+		if strings.HasPrefix(job.ID, toComplete) {
+			ids = append(ids, job.ID+"\t"+job.Kind+", "+string(job.Status))
+		}
+	}
+	return ids, cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,20 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/EnSync-engine/CLI/app/api"
+	"github.com/EnSync-engine/CLI/app/domain"
+)
+
+func listAllAccessKeys(ctx context.Context, client api.AccessKeyService) ([]*domain.AccessKeyPermissions, error) {
+	return api.CollectPages(ctx, nil, api.AccessKeyPages(client))
+}
+
+func listAllWorkspaces(ctx context.Context, client api.WorkspaceService) ([]*domain.Workspace, error) {
+	return api.CollectPages(ctx, nil, api.WorkspacePages(client))
+}
+
+func listAllEvents(ctx context.Context, client api.EventService) ([]*domain.Event, error) {
+	return api.CollectPages(ctx, nil, api.EventPages(client))
+}
@@ -1,46 +1,74 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 
 	"github.com/EnSync-engine/CLI/app/api"
+	"github.com/EnSync-engine/CLI/app/completion"
 	"github.com/EnSync-engine/CLI/app/config"
+	"github.com/EnSync-engine/CLI/app/domain"
+)
+
+const (
+	// retriesFromConfig is the --retries default, meaning the flag was not set.
+	retriesFromConfig = -1
+
+	defaultRequestsPerSecond = 10
+	defaultBurst             = 20
 )
 
 var (
-	cfgFile string
-	debug   bool
+	cfgFile        string
+	profile        string
+	workspacePath  string
+	debug          bool
+	noCache        bool
+	cacheTTL       cacheTTLValue
+	idempotencyKey string
+	requestID      string
+	retries        int
+
+	// loadedConfig is set once the client has been configured, so that
+	// commands run repeatedly in one process (see `ensync shell`) share a
+	// single client and session.
+	loadedConfig *config.Config
 )
 
 func Execute() error {
-	cfg, err := config.Load()
-	if err != nil {
-		return fmt.Errorf("load configuration: %w", err)
-	}
+	// The client is configured from flags and the config file in
+	// setupClient, once cobra has parsed the command line.
+	client := api.NewClient("")
 
-	logger := initLogger(cfg)
-	zap.ReplaceGlobals(logger)
-
-	client := api.NewClient(
-		cfg.BaseURL,
-		api.WithLogger(logger),
-		api.WithRateLimit(10, 20),
-	)
+	defer flushTelemetry()
+	return newCommandTree(client).Execute()
+}
 
+// newCommandTree builds the root command with every subcommand bound to client.
+func newCommandTree(client *api.Client) *cobra.Command {
 	rootCmd := newRootCmd()
 	rootCmd.AddCommand(
 		newEventCmd(client),
 		newAccessKeyCmd(client),
 		newWorkspaceCmd(client),
+		newUICmd(client),
+		newEditCmd(client),
+		newShellCmd(client),
+		newJobsCmd(client),
+		newDoctorCmd(client),
+		newCompletionCmd(),
+		newCacheCmd(),
+		newBundleCmd(),
 		newVersionCmd(),
 	)
-
-	return rootCmd.Execute()
+	_ = rootCmd.RegisterFlagCompletionFunc("workspace", completeResourceFlag(client, completion.Workspaces))
+	return rootCmd
 }
 
 func newRootCmd() *cobra.Command {
@@ -50,17 +78,283 @@ func newRootCmd() *cobra.Command {
 		Long: `EnSync CLI provides commands for managing events and access keys
 in the EnSync real-time messaging system.
 
-Use --access-key flag with subcommands to authenticate API requests.`,
+Authenticate API requests with the --access-key flag of subcommands, or
+configure auth for the profile in the config file.`,
 		SilenceUsage:  true,
 		SilenceErrors: true,
+		// Replaced by newCompletionCmd, which documents resource completion.
+		CompletionOptions: cobra.CompletionOptions{DisableDefaultCmd: true},
 	}
 
 	cmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.ensync/config.yaml)")
+	cmd.PersistentFlags().StringVar(&profile, "profile", "", "config profile to use (default from ENSYNC_PROFILE or default_profile)")
+	cmd.PersistentFlags().StringVar(&workspacePath, "workspace", "", "workspace path to scope event and access key commands to")
 	cmd.PersistentFlags().BoolVar(&debug, "debug", false, "enable debug logging")
+	cmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "always fetch responses from the server")
+	cacheTTL = cacheTTLValue{ttl: defaultCacheTTL}
+	cmd.PersistentFlags().Var(&cacheTTL, "cache-ttl", "how long cached responses are used before revalidating; when given, also applies to responses without a max-age")
+	cmd.PersistentFlags().IntVar(&retries, "retries", retriesFromConfig, "maximum retries per request; -1 uses the configured value (3 if unset)")
+	cmd.PersistentFlags().StringVar(&idempotencyKey, "idempotency-key", "", "Idempotency-Key sent with the command's writes (default random per write)")
+	cmd.PersistentFlags().StringVar(&requestID, "request-id", "", "X-Request-ID sent with the command's requests (default random per request)")
+	cmd.PersistentFlags().StringVar(&traceExporter, "trace", "", "export request traces and metrics: otlp, stdout (written to stderr) or file")
+	cmd.PersistentFlags().StringVar(&traceEndpoint, "trace-endpoint", "", "OTLP/HTTP collector URL for --trace otlp (default $OTEL_EXPORTER_OTLP_ENDPOINT or "+defaultOTLPEndpoint+")")
+	cmd.PersistentFlags().StringVar(&traceFilePath, "trace-file", "", "file --trace file appends spans and metrics to")
 
 	return cmd
 }
 
+// setupClient loads the configuration selected by the global flags and
+// applies it to the client.
+func setupClient(client *api.Client) (*config.Config, error) {
+	// The idempotency key and request ID belong to this command rather than
+	// the session, so they are applied even when the configuration is
+	// already loaded.
+	client.SetIdempotencyKey(idempotencyKey)
+	client.SetRequestID(requestID)
+
+	if loadedConfig != nil {
+		return loadedConfig, nil
+	}
+
+	cfg, err := config.Load(cfgFile, profile)
+	if err != nil {
+		return nil, fmt.Errorf("load configuration: %w", err)
+	}
+
+	policy, err := retryPolicy(cfg.Retry)
+	if err != nil {
+		return nil, err
+	}
+	rps, burst, err := rateLimit(cfg.RateLimit)
+	if err != nil {
+		return nil, err
+	}
+	breaker, err := circuitBreaker(cfg.CircuitBreaker)
+	if err != nil {
+		return nil, err
+	}
+	connection, err := connectionOptions(cfg.TLS, cfg.Proxy)
+	if err != nil {
+		return nil, err
+	}
+	auth, err := authenticator(cfg.Auth)
+	if err != nil {
+		return nil, err
+	}
+	signingKey, err := signingKeyPair(cfg.Signing)
+	if err != nil {
+		return nil, err
+	}
+
+	telemetry, err := telemetryOption()
+	if err != nil {
+		return nil, err
+	}
+
+	logger := initLogger(cfg)
+	zap.ReplaceGlobals(logger)
+
+	options := []api.ClientOption{
+		api.WithBaseURL(cfg.BaseURL),
+		api.WithLogger(logger),
+		api.WithRateLimit(rps, burst),
+		api.WithThrottleHandler(printThrottled),
+		api.WithRetryPolicy(policy),
+		api.WithCircuitBreaker(breaker),
+		api.WithCache(responseCache()),
+		api.WithSigning(signingKey),
+		api.WithOmitAccessKey(cfg.Signing.OmitAccessKey != nil && *cfg.Signing.OmitAccessKey),
+	}
+	options = append(options, connection...)
+	if telemetry != nil {
+		options = append(options, telemetry)
+	}
+	client.Configure(options...)
+
+	profileAuth = auth
+	loadedConfig = cfg
+	return cfg, nil
+}
+
+// retryPolicy returns the client's default retry policy overridden by the
+// config file and the --retries flag.
+func retryPolicy(settings config.RetrySettings) (api.RetryPolicy, error) {
+	policy := api.DefaultRetryPolicy()
+
+	if settings.MaxRetries != nil {
+		policy.MaxRetries = *settings.MaxRetries
+	}
+	if retries != retriesFromConfig {
+		policy.MaxRetries = retries
+	}
+	if settings.WaitMin != 0 {
+		policy.WaitMin = settings.WaitMin
+	}
+	if settings.WaitMax != 0 {
+		policy.WaitMax = settings.WaitMax
+	}
+	if settings.Backoff != "" {
+		backoff, err := api.ParseBackoff(settings.Backoff)
+		if err != nil {
+			return policy, fmt.Errorf("retry.backoff: %w", err)
+		}
+		policy.Backoff = backoff
+	}
+	if settings.StatusCodes != nil {
+		policy.StatusCodes = settings.StatusCodes
+	}
+	if settings.RespectRetryAfter != nil {
+		policy.RespectRetryAfter = *settings.RespectRetryAfter
+	}
+
+	switch {
+	case policy.MaxRetries < 0:
+		return policy, fmt.Errorf("retries must not be negative, got %d", policy.MaxRetries)
+	case policy.WaitMax < policy.WaitMin:
+		return policy, fmt.Errorf("retry.wait_max (%s) must not be less than retry.wait_min (%s)", policy.WaitMax, policy.WaitMin)
+	}
+
+	return policy, nil
+}
+
+// rateLimit returns the static request rate and burst, taken from the
+// config file or the defaults.
+func rateLimit(settings config.RateLimitSettings) (float64, int, error) {
+	rps, burst := float64(defaultRequestsPerSecond), defaultBurst
+	if settings.RequestsPerSecond != 0 {
+		rps = settings.RequestsPerSecond
+	}
+	if settings.Burst != 0 {
+		burst = settings.Burst
+	}
+
+	switch {
+	case rps <= 0:
+		return 0, 0, fmt.Errorf("rate_limit.requests_per_second must be positive, got %g", rps)
+	case burst < 1:
+		return 0, 0, fmt.Errorf("rate_limit.burst must be at least 1, got %d", burst)
+	}
+	return rps, burst, nil
+}
+
+// circuitBreaker returns a circuit breaker following the client's default
+// policy overridden by the config file, or nil if it is disabled.
+func circuitBreaker(settings config.CircuitBreakerSettings) (*api.CircuitBreaker, error) {
+	if settings.Enabled != nil && !*settings.Enabled {
+		return nil, nil
+	}
+
+	policy := api.DefaultCircuitBreakerPolicy()
+	if settings.ConsecutiveFailures != nil {
+		policy.ConsecutiveFailures = *settings.ConsecutiveFailures
+	}
+	if settings.FailureRate != nil {
+		policy.FailureRate = *settings.FailureRate
+	}
+	if settings.Window != 0 {
+		policy.Window = settings.Window
+	}
+	if settings.OpenTimeout != 0 {
+		policy.OpenTimeout = settings.OpenTimeout
+	}
+
+	switch {
+	case policy.ConsecutiveFailures < 0:
+		return nil, fmt.Errorf("circuit_breaker.consecutive_failures must not be negative, got %d", policy.ConsecutiveFailures)
+	case policy.FailureRate < 0 || policy.FailureRate > 1:
+		return nil, fmt.Errorf("circuit_breaker.failure_rate must be between 0 and 1, got %g", policy.FailureRate)
+	case policy.Window < 1:
+		return nil, fmt.Errorf("circuit_breaker.window must be at least 1, got %d", policy.Window)
+	case policy.OpenTimeout < 0:
+		return nil, fmt.Errorf("circuit_breaker.open_timeout must not be negative, got %s", policy.OpenTimeout)
+	}
+	return api.NewCircuitBreaker(policy), nil
+}
+
+// connectionOptions returns the client options for the configured TLS and
+// proxy settings.
+func connectionOptions(tlsSettings config.TLSSettings, proxySettings config.ProxySettings) ([]api.ClientOption, error) {
+	var options []api.ClientOption
+
+	opts := api.TLSOptions{
+		CAFile:             tlsSettings.CAFile,
+		CertFile:           tlsSettings.CertFile,
+		KeyFile:            tlsSettings.KeyFile,
+		InsecureSkipVerify: tlsSettings.InsecureSkipVerify != nil && *tlsSettings.InsecureSkipVerify,
+	}
+	if tlsSettings.MinVersion != "" {
+		version, err := api.ParseTLSVersion(tlsSettings.MinVersion)
+		if err != nil {
+			return nil, fmt.Errorf("tls.min_version: %w", err)
+		}
+		opts.MinVersion = version
+	}
+	if opts != (api.TLSOptions{}) {
+		tlsConfig, err := api.NewTLSConfig(opts)
+		if err != nil {
+			return nil, fmt.Errorf("tls: %w", err)
+		}
+		options = append(options, api.WithTLSConfig(tlsConfig))
+	}
+	if opts.InsecureSkipVerify {
+		_, _ = fmt.Fprintln(os.Stderr, "WARNING: TLS certificate verification is disabled (tls.insecure_skip_verify). "+
+			"Anyone on the network path can intercept your access key; use tls.ca_file instead.")
+	}
+
+	if proxySettings != (config.ProxySettings{}) {
+		proxy, err := api.NewProxyFunc(proxySettings.URL, proxySettings.NoProxy)
+		if err != nil {
+			return nil, fmt.Errorf("proxy: %w", err)
+		}
+		options = append(options, api.WithProxy(proxy))
+	}
+
+	return options, nil
+}
+
+// signingKeyPair reads the key pair requests are signed with, nil if
+// signing is not configured.
+func signingKeyPair(settings config.SigningSettings) (*domain.ServiceKeyPair, error) {
+	if settings.KeyPairFile == "" {
+		if settings.OmitAccessKey != nil && *settings.OmitAccessKey {
+			return nil, fmt.Errorf("signing.omit_access_key requires signing.key_pair_file")
+		}
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(settings.KeyPairFile)
+	if err != nil {
+		return nil, fmt.Errorf("signing.key_pair_file: %w", err)
+	}
+	var keyPair domain.ServiceKeyPair
+	if err := json.Unmarshal(data, &keyPair); err != nil {
+		return nil, fmt.Errorf("signing.key_pair_file %q: %w", settings.KeyPairFile, err)
+	}
+	if keyPair.PublicKey == "" || keyPair.PrivateKey == "" {
+		return nil, fmt.Errorf("signing.key_pair_file %q: needs both public_key and private_key", settings.KeyPairFile)
+	}
+	return &keyPair, nil
+}
+
+// printThrottled tells the user why the command has paused when the
+// server's rate limit is exhausted.
+func printThrottled(wait time.Duration) {
+	precision := time.Second
+	if wait < time.Second {
+		precision = time.Millisecond
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "Rate limited by the server: throttled for %s\n", wait.Round(precision))
+}
+
+// workspaceScope returns the workspace set by --workspace, falling back to
+// the one configured for the active profile.
+func workspaceScope(cfg *config.Config) string {
+	if workspacePath != "" {
+		return workspacePath
+	}
+	return cfg.Workspace
+}
+
 func initLogger(cfg *config.Config) *zap.Logger {
 	level := zapcore.InfoLevel
 	if debug || cfg.Debug {
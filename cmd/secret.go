@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/EnSync-engine/CLI/app/secrets"
+)
+
+const (
+	envVaultAddr  = "VAULT_ADDR"
+	envVaultToken = "VAULT_TOKEN"
+
+	secretSinkUsage = "dotenv:<file>, kubernetes:<file> or vault:<mount>/<path>"
+)
+
+// secretSinks open the sinks of --secret-sink, by the kind prefixing its
+// value.
+var secretSinks = map[string]func(target string) (secrets.Sink, error){
+	"dotenv":     secrets.NewDotenvSink,
+	"kubernetes": secrets.NewKubernetesSink,
+	"vault":      openVaultSink,
+}
+
+// secretOptions are the flags choosing where commands issuing private keys
+// put them. Private keys are only printed with --show-secret.
+type secretOptions struct {
+	file           string
+	bundlePath     string
+	passphraseFile string
+	sink           string
+	show           bool
+}
+
+func addSecretFlags(cmd *cobra.Command, opts *secretOptions) {
+	cmd.Flags().StringVar(&opts.file, "secret-file", "", "write private keys to this new file, readable only by you")
+	cmd.Flags().StringVar(&opts.bundlePath, "bundle", "", "write private keys to this new encrypted bundle")
+	cmd.Flags().StringVar(&opts.passphraseFile, "passphrase-file", "", passphraseFileUsage)
+	cmd.Flags().StringVar(&opts.sink, "secret-sink", "", "write private keys to a sink: "+secretSinkUsage)
+	cmd.Flags().BoolVar(&opts.show, "show-secret", false, "print private keys to stdout")
+	cmd.MarkFlagsMutuallyExclusive("secret-file", "bundle", "secret-sink")
+}
+
+// set reports whether any destination for private keys was given.
+func (o *secretOptions) set() bool {
+	return o.file != "" || o.bundlePath != "" || o.sink != "" || o.show
+}
+
+// open returns the sink selected by the flags, or nil if there is none.
+// Without a sink, --show-secret is required, so private keys are never
+// silently lost.
+func (o *secretOptions) open(cmd *cobra.Command) (secrets.Sink, error) {
+	if o.passphraseFile != "" && o.bundlePath == "" {
+		return nil, fmt.Errorf("--passphrase-file requires --bundle")
+	}
+
+	switch {
+	case o.file != "":
+		return secrets.NewFileSink(o.file)
+	case o.bundlePath != "":
+		// Fail before prompting when the bundle cannot be written anyway.
+		if _, err := os.Stat(o.bundlePath); err == nil {
+			return nil, fmt.Errorf("bundle %q already exists", o.bundlePath)
+		}
+		passphrase, err := readPassphrase(cmd, o.passphraseFile, true)
+		if err != nil {
+			return nil, err
+		}
+		return secrets.NewBundleSink(o.bundlePath, passphrase)
+	case o.sink != "":
+		kind, target, _ := strings.Cut(o.sink, ":")
+		open, ok := secretSinks[kind]
+		if !ok || target == "" {
+			return nil, fmt.Errorf("invalid --secret-sink %q: use %s", o.sink, secretSinkUsage)
+		}
+		return open(target)
+	case o.show:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("the new private key is only returned once: save it with --secret-file, --bundle or --secret-sink, or print it with --show-secret")
+	}
+}
+
+// openVaultSink writes to the KV version 2 engine at $VAULT_ADDR, with
+// $VAULT_TOKEN, as the Vault CLI does.
+func openVaultSink(target string) (secrets.Sink, error) {
+	mount, path, _ := strings.Cut(strings.Trim(target, "/"), "/")
+	if os.Getenv(envVaultAddr) == "" || os.Getenv(envVaultToken) == "" {
+		return nil, fmt.Errorf("the vault sink needs %s and %s", envVaultAddr, envVaultToken)
+	}
+	return secrets.NewVaultSink(secrets.VaultOptions{
+		Address: os.Getenv(envVaultAddr),
+		Token:   os.Getenv(envVaultToken),
+		Mount:   mount,
+		Path:    path,
+	})
+}
@@ -0,0 +1,375 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/chzyer/readline"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/EnSync-engine/CLI/app/api"
+	"github.com/EnSync-engine/CLI/app/config"
+	"github.com/EnSync-engine/CLI/pkg/shellwords"
+)
+
+const (
+	shellHistoryFile = "shell_history"
+	lastResultVar    = "last"
+)
+
+// sessionFlags configure the client, which the shell sets up once when it
+// starts. Given for a single command they would silently have no effect.
+var sessionFlags = []string{"config", "profile", "debug", "no-cache", "cache-ttl", "retries", "trace", "trace-endpoint", "trace-file"}
+
+const shellHelp = `Run any ensync command without the "ensync" prefix, e.g. "event list".
+
+Built-ins:
+  set NAME VALUE   store a variable, referenced later as $NAME
+  unset NAME       remove a variable
+  vars             list variables
+  reload           refresh the resource names used for tab completion
+  help [command]   show this help or a command's help
+  exit, quit       leave the shell
+
+$last holds the output of the previous command. JSON values can be
+indexed with dots, e.g. $last.results.0.name or ${last.results.0.id}.
+
+Commands can be piped: the output of one becomes the input of the next.
+Prefix a stage with "!" to run it in your system shell, e.g.
+  event list | !jq -r '.results[].name'
+
+--config, --profile, --debug, --no-cache, --cache-ttl, --retries and the
+--trace flags apply to the whole shell: give them when starting it. Lines
+containing --access-key are not saved to the history.`
+
+// shellSession holds the state kept between commands in `ensync shell`.
+type shellSession struct {
+	client    *api.Client
+	accessKey string
+	workspace string
+	debug     bool
+
+	vars map[string]string
+
+	out    io.Writer
+	errOut io.Writer
+}
+
+func newShellCmd(client *api.Client) *cobra.Command {
+	var accessKey string
+
+	cmd := &cobra.Command{
+		Use:   "shell",
+		Short: "Start an interactive shell that keeps one API session",
+		Long: `Start an interactive shell. The configuration, API client and
+credentials are set up once and reused for every command.
+
+` + shellHelp,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if _, err := setupClient(client); err != nil {
+				return err
+			}
+			_, err := authenticate(client, accessKey)
+			return err
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			session := &shellSession{
+				client:    client,
+				accessKey: accessKey,
+				workspace: workspacePath,
+				debug:     debug,
+				vars:      make(map[string]string),
+				out:       cmd.OutOrStdout(),
+				errOut:    cmd.ErrOrStderr(),
+			}
+			return session.run(cmd.Context())
+		},
+	}
+
+	cmd.Flags().StringVar(&accessKey, "access-key", "", accessKeyUsage)
+
+	return cmd
+}
+
+func (s *shellSession) run(ctx context.Context) error {
+	if err := os.MkdirAll(config.Dir(), 0o700); err != nil {
+		return fmt.Errorf("create config directory: %w", err)
+	}
+
+	rl, err := readline.NewEx(&readline.Config{
+		Prompt:          s.prompt(),
+		HistoryFile:     filepath.Join(config.Dir(), shellHistoryFile),
+		AutoComplete:    &shellCompleter{session: s},
+		InterruptPrompt: "^C",
+		EOFPrompt:       "exit",
+		// Lines are saved by the loop, which leaves out credentials.
+		DisableAutoSaveHistory: true,
+	})
+	if err != nil {
+		return fmt.Errorf("start shell: %w", err)
+	}
+	defer func() { _ = rl.Close() }()
+
+	_, _ = fmt.Fprintln(s.out, `EnSync shell. Type "help" for help, "exit" to quit.`)
+
+	for {
+		rl.SetPrompt(s.prompt())
+		line, err := rl.Readline()
+		if errors.Is(err, readline.ErrInterrupt) {
+			continue
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if !strings.Contains(line, "--access-key") {
+			_ = rl.SaveHistory(line)
+		}
+
+		quit, err := s.runLine(ctx, line)
+		if err != nil {
+			_, _ = fmt.Fprintf(s.errOut, "Error: %v\n", err)
+		}
+		if quit {
+			return nil
+		}
+	}
+}
+
+func (s *shellSession) prompt() string {
+	if s.workspace != "" {
+		return fmt.Sprintf("ensync [%s]> ", s.workspace)
+	}
+	return "ensync> "
+}
+
+// runLine executes one line of input and reports whether the shell should exit.
+func (s *shellSession) runLine(ctx context.Context, line string) (bool, error) {
+	stages := shellwords.SplitPipeline(line)
+
+	if len(stages) == 1 && !strings.HasPrefix(stages[0], "!") {
+		args, err := shellwords.Tokenize(stages[0], s.lookupVariable)
+		if err != nil {
+			return false, err
+		}
+		if handled, quit, err := s.runBuiltin(args); handled {
+			return quit, err
+		}
+	}
+
+	var (
+		input  io.Reader = os.Stdin
+		output bytes.Buffer
+	)
+
+	for i, stage := range stages {
+		if stage == "" {
+			return false, fmt.Errorf("empty pipeline stage")
+		}
+
+		output.Reset()
+		var out io.Writer = &output
+		if i == len(stages)-1 {
+			out = io.MultiWriter(s.out, &output)
+		}
+
+		if err := s.runStage(ctx, stage, input, out); err != nil {
+			return false, err
+		}
+
+		input = bytes.NewReader(bytes.Clone(output.Bytes()))
+	}
+
+	s.vars[lastResultVar] = output.String()
+	return false, nil
+}
+
+// runBuiltin handles the shell's own commands. Built-ins leave $last untouched.
+func (s *shellSession) runBuiltin(args []string) (handled, quit bool, err error) {
+	if len(args) > 0 && args[0] == "ensync" {
+		args = args[1:]
+	}
+	if len(args) == 0 {
+		return true, false, nil
+	}
+
+	switch args[0] {
+	case "exit", "quit":
+		return true, true, nil
+	case "help":
+		if len(args) > 1 {
+			return false, false, nil
+		}
+		_, _ = fmt.Fprintln(s.out, shellHelp)
+	case "set":
+		if len(args) < 3 {
+			return true, false, fmt.Errorf("usage: set NAME VALUE")
+		}
+		s.vars[args[1]] = strings.Join(args[2:], " ")
+	case "unset":
+		for _, name := range args[1:] {
+			delete(s.vars, name)
+		}
+	case "vars":
+		s.printVars(s.out)
+	case "reload":
+		if err := completionCache().Clear(); err != nil {
+			return true, false, err
+		}
+		_, _ = fmt.Fprintln(s.out, "Resource names will be reloaded on next completion")
+	case "shell":
+		return true, false, fmt.Errorf("already in a shell")
+	default:
+		return false, false, nil
+	}
+
+	return true, false, nil
+}
+
+func (s *shellSession) runStage(ctx context.Context, stage string, in io.Reader, out io.Writer) error {
+	if strings.HasPrefix(stage, "!") {
+		return s.runExternal(ctx, strings.TrimPrefix(stage, "!"), in, out)
+	}
+
+	args, err := shellwords.Tokenize(stage, s.lookupVariable)
+	if err != nil {
+		return err
+	}
+	if len(args) > 0 && args[0] == "ensync" {
+		args = args[1:]
+	}
+	if len(args) == 0 {
+		return nil
+	}
+	if args[0] == "shell" {
+		return fmt.Errorf("already in a shell")
+	}
+
+	return s.runCommand(ctx, args, in, out)
+}
+
+// runCommand runs args through a fresh command tree bound to the
+// session's client, so flag values never leak between commands.
+func (s *shellSession) runCommand(ctx context.Context, args []string, in io.Reader, out io.Writer) error {
+	if err := checkSessionFlags(args); err != nil {
+		return err
+	}
+
+	root := s.newCommandTree()
+	root.SetArgs(args)
+	root.SetIn(in)
+	root.SetOut(out)
+	root.SetErr(s.errOut)
+
+	return root.ExecuteContext(ctx)
+}
+
+// checkSessionFlags rejects the sessionFlags in args.
+func checkSessionFlags(args []string) error {
+	for _, arg := range args {
+		if arg == "--" {
+			return nil
+		}
+		name, ok := strings.CutPrefix(arg, "--")
+		if !ok {
+			continue
+		}
+		name, _, _ = strings.Cut(name, "=")
+		if slices.Contains(sessionFlags, name) {
+			return fmt.Errorf("--%s applies to the whole shell: restart it as \"ensync --%s ... shell\"", name, name)
+		}
+	}
+	return nil
+}
+
+// newCommandTree builds a command tree bound to the session's client.
+func (s *shellSession) newCommandTree() *cobra.Command {
+	root := newCommandTree(s.client)
+
+	// Building the tree resets the global flag variables to their
+	// defaults; restore the values the shell was started with.
+	workspacePath = s.workspace
+	debug = s.debug
+	presetFlag(root, "access-key", s.accessKey)
+
+	return root
+}
+
+// presetFlag sets the named flag on every command that defines it.
+func presetFlag(cmd *cobra.Command, name, value string) {
+	for _, flags := range []*pflag.FlagSet{cmd.Flags(), cmd.PersistentFlags()} {
+		if flag := flags.Lookup(name); flag != nil && !flag.Changed {
+			_ = flags.Set(name, value)
+		}
+	}
+	for _, child := range cmd.Commands() {
+		presetFlag(child, name, value)
+	}
+}
+
+func (s *shellSession) runExternal(ctx context.Context, command string, in io.Reader, out io.Writer) error {
+	var external *exec.Cmd
+	if runtime.GOOS == "windows" {
+		external = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		external = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	external.Stdin = in
+	external.Stdout = out
+	external.Stderr = s.errOut
+
+	if err := external.Run(); err != nil {
+		return fmt.Errorf("%s: %w", strings.TrimSpace(command), err)
+	}
+	return nil
+}
+
+// lookupVariable resolves NAME or NAME.path.to.field against the session variables.
+func (s *shellSession) lookupVariable(ref string) (string, error) {
+	name, path, _ := strings.Cut(ref, ".")
+
+	value, ok := s.vars[name]
+	if !ok {
+		return "", fmt.Errorf("undefined variable $%s", name)
+	}
+
+	resolved, err := shellwords.LookupPath(value, path)
+	if err != nil {
+		return "", fmt.Errorf("$%s: %w", ref, err)
+	}
+	return resolved, nil
+}
+
+func (s *shellSession) printVars(out io.Writer) {
+	names := make([]string, 0, len(s.vars))
+	for name := range s.vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		value := strings.TrimSpace(s.vars[name])
+		if len(value) > 60 {
+			value = value[:57] + "..."
+		}
+		_, _ = fmt.Fprintf(out, "%s = %s\n", name, strings.ReplaceAll(value, "\n", " "))
+	}
+}
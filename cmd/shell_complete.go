@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// shellCompleter completes command names, flags and live resource names
+// for the shell's line editor.
+type shellCompleter struct {
+	session *shellSession
+}
+
+func (c *shellCompleter) Do(line []rune, pos int) ([][]rune, int) {
+	input := string(line[:pos])
+	if idx := strings.LastIndex(input, "|"); idx >= 0 {
+		input = input[idx+1:]
+	}
+	if strings.HasPrefix(strings.TrimSpace(input), "!") {
+		return nil, 0
+	}
+
+	words := strings.Fields(input)
+	current := ""
+	if len(words) > 0 && !strings.HasSuffix(input, " ") {
+		current = words[len(words)-1]
+		words = words[:len(words)-1]
+	}
+	if len(words) > 0 && words[0] == "ensync" {
+		words = words[1:]
+	}
+
+	candidates := c.candidates(words, current)
+
+	var suggestions [][]rune
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, current) && candidate != current {
+			suggestions = append(suggestions, []rune(candidate[len(current):]+" "))
+		}
+	}
+	return suggestions, len([]rune(current))
+}
+
+func (c *shellCompleter) candidates(words []string, current string) []string {
+	root := c.session.newCommandTree()
+	cmd, positional := findCommand(root, words)
+
+	if strings.HasPrefix(current, "-") {
+		return flagNames(cmd)
+	}
+
+	if cmd == root && len(positional) == 0 {
+		return append(subcommandNames(cmd), "exit", "help", "reload", "set", "unset", "vars")
+	}
+	if cmd.HasAvailableSubCommands() && len(positional) == 0 {
+		return subcommandNames(cmd)
+	}
+
+	if cmd.ValidArgsFunction != nil {
+		cmd.SetContext(context.Background())
+		names, _ := cmd.ValidArgsFunction(cmd, positional, current)
+		for i, name := range names {
+			// Drop cobra's tab-separated descriptions.
+			names[i], _, _ = strings.Cut(name, "\t")
+		}
+		return names
+	}
+	return nil
+}
+
+// findCommand walks the command tree along words, skipping flags and
+// their values, and returns the deepest command plus its positional args.
+func findCommand(root *cobra.Command, words []string) (*cobra.Command, []string) {
+	cmd := root
+	var positional []string
+
+	for i := 0; i < len(words); i++ {
+		word := words[i]
+		if strings.HasPrefix(word, "-") {
+			name := strings.TrimLeft(word, "-")
+			if flag := lookupFlag(cmd, name); flag != nil && flag.Value.Type() != "bool" && !strings.Contains(word, "=") {
+				i++
+			}
+			continue
+		}
+
+		if len(positional) == 0 {
+			if child, _, err := cmd.Find([]string{word}); err == nil && child != cmd {
+				cmd = child
+				continue
+			}
+		}
+		positional = append(positional, word)
+	}
+
+	return cmd, positional
+}
+
+func lookupFlag(cmd *cobra.Command, name string) *pflag.Flag {
+	if len(name) == 1 {
+		if flag := cmd.Flags().ShorthandLookup(name); flag != nil {
+			return flag
+		}
+		return cmd.InheritedFlags().ShorthandLookup(name)
+	}
+	if flag := cmd.Flags().Lookup(name); flag != nil {
+		return flag
+	}
+	return cmd.InheritedFlags().Lookup(name)
+}
+
+func subcommandNames(cmd *cobra.Command) []string {
+	var names []string
+	for _, child := range cmd.Commands() {
+		if child.IsAvailableCommand() && child.Name() != "shell" {
+			names = append(names, child.Name())
+		}
+	}
+	return names
+}
+
+func flagNames(cmd *cobra.Command) []string {
+	var names []string
+	add := func(flag *pflag.Flag) {
+		if !flag.Hidden && flag.Name != "access-key" {
+			names = append(names, "--"+flag.Name)
+		}
+	}
+	cmd.Flags().VisitAll(add)
+	cmd.InheritedFlags().VisitAll(add)
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdoutmetric"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+
+	"github.com/EnSync-engine/CLI/app/api"
+	"github.com/EnSync-engine/CLI/pkg/version"
+)
+
+const (
+	traceOTLP   = "otlp"
+	traceStdout = "stdout"
+	traceFile   = "file"
+
+	serviceName = "ensync-cli"
+
+	// defaultOTLPEndpoint is a collector on this machine, used unless
+	// --trace-endpoint or OTEL_EXPORTER_OTLP_ENDPOINT says otherwise.
+	defaultOTLPEndpoint = "http://localhost:4318"
+	envOTLPEndpoint     = "OTEL_EXPORTER_OTLP_ENDPOINT"
+
+	telemetryShutdownTimeout = 5 * time.Second
+)
+
+var (
+	traceExporter string
+	traceEndpoint string
+	traceFilePath string
+
+	// shutdownTelemetry flushes the spans and metrics recorded by the
+	// process. It is set once --trace is in effect.
+	shutdownTelemetry func(ctx context.Context) error
+
+	// exportErr is the first error the exporters reported in the
+	// background, shown when the command ends.
+	exportErrMu sync.Mutex
+	exportErr   error
+)
+
+// telemetryOption returns the client option exporting traces and metrics
+// as selected by --trace, or nil when tracing is off.
+func telemetryOption() (api.ClientOption, error) {
+	if traceExporter == "" {
+		return nil, nil
+	}
+
+	spans, metrics, closeOutput, err := telemetryExporters(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		semconv.ServiceName(serviceName),
+		semconv.ServiceVersion(version.Get().Version),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("build telemetry resource: %w", err)
+	}
+
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		exportErrMu.Lock()
+		defer exportErrMu.Unlock()
+		if exportErr == nil {
+			exportErr = err
+		}
+	}))
+
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithBatcher(spans), sdktrace.WithResource(res))
+	meterProvider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(sdkmetric.NewPeriodicReader(metrics)), sdkmetric.WithResource(res))
+
+	shutdownTelemetry = func(ctx context.Context) error {
+		return errors.Join(tracerProvider.Shutdown(ctx), meterProvider.Shutdown(ctx), closeOutput())
+	}
+	return api.WithTelemetry(tracerProvider, meterProvider), nil
+}
+
+// telemetryExporters creates the span and metric exporters named by
+// --trace, along with a function closing the file they write to, if any.
+func telemetryExporters(ctx context.Context) (sdktrace.SpanExporter, sdkmetric.Exporter, func() error, error) {
+	noClose := func() error { return nil }
+
+	switch traceExporter {
+	case traceOTLP:
+		var traceOpts []otlptracehttp.Option
+		var metricOpts []otlpmetrichttp.Option
+		endpoint := traceEndpoint
+		if endpoint == "" && os.Getenv(envOTLPEndpoint) == "" {
+			endpoint = defaultOTLPEndpoint
+		}
+		if endpoint != "" {
+			endpoint = strings.TrimSuffix(endpoint, "/")
+			traceOpts = append(traceOpts, otlptracehttp.WithEndpointURL(endpoint+"/v1/traces"))
+			metricOpts = append(metricOpts, otlpmetrichttp.WithEndpointURL(endpoint+"/v1/metrics"))
+		}
+
+		spans, err := otlptracehttp.New(ctx, traceOpts...)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("create OTLP trace exporter: %w", err)
+		}
+		metrics, err := otlpmetrichttp.New(ctx, metricOpts...)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("create OTLP metric exporter: %w", err)
+		}
+		return spans, metrics, noClose, nil
+
+	case traceStdout:
+		// Despite its conventional name, the exporter writes to stderr so
+		// that it never mixes with the command's output, which is often
+		// JSON piped into another program.
+		spans, metrics, err := writerExporters(os.Stderr)
+		return spans, metrics, noClose, err
+
+	case traceFile:
+		if traceFilePath == "" {
+			return nil, nil, nil, errors.New("--trace file requires --trace-file")
+		}
+		file, err := os.OpenFile(traceFilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("open trace file %q: %w", traceFilePath, err)
+		}
+		spans, metrics, err := writerExporters(file)
+		if err != nil {
+			_ = file.Close()
+			return nil, nil, nil, err
+		}
+		return spans, metrics, file.Close, nil
+
+	default:
+		return nil, nil, nil, fmt.Errorf("unknown --trace exporter %q: use otlp, stdout or file", traceExporter)
+	}
+}
+
+// writerExporters returns exporters writing spans and metrics to w as JSON.
+func writerExporters(w io.Writer) (sdktrace.SpanExporter, sdkmetric.Exporter, error) {
+	spans, err := stdouttrace.New(stdouttrace.WithWriter(w))
+	if err != nil {
+		return nil, nil, fmt.Errorf("create trace exporter: %w", err)
+	}
+	metrics, err := stdoutmetric.New(stdoutmetric.WithWriter(w))
+	if err != nil {
+		return nil, nil, fmt.Errorf("create metric exporter: %w", err)
+	}
+	return spans, metrics, nil
+}
+
+// flushTelemetry exports whatever telemetry the process has not exported
+// yet. Failing to export never fails the command.
+func flushTelemetry() {
+	if shutdownTelemetry == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), telemetryShutdownTimeout)
+	defer cancel()
+	err := shutdownTelemetry(ctx)
+
+	exportErrMu.Lock()
+	defer exportErrMu.Unlock()
+	if err == nil {
+		err = exportErr
+	}
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Warning: could not export telemetry: %v\n", err)
+	}
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	"github.com/EnSync-engine/CLI/app/api"
+	"github.com/EnSync-engine/CLI/app/tui"
+)
+
+func newUICmd(client *api.Client) *cobra.Command {
+	var accessKey string
+
+	cmd := &cobra.Command{
+		Use:   "ui",
+		Short: "Browse events, access keys and workspaces in a terminal UI",
+		Long: `Open a full-screen terminal UI with tabs for events, access keys and
+workspaces.
+
+Keys: tab/shift+tab switch tabs, ↑/↓ move, / searches as you type,
+ctrl+u/ctrl+d scroll the detail pane, e edits an event payload in
+$EDITOR, r rotates an access key, d deletes the selection, ctrl+r
+reloads and q quits.`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := setupClient(client)
+			if err != nil {
+				return err
+			}
+			// Request logs and throttling notices would draw over the
+			// full-screen UI; failures are shown in its status line instead.
+			client.Configure(api.WithLogger(zap.NewNop()), api.WithThrottleHandler(nil))
+			if _, err := authenticate(client, accessKey); err != nil {
+				return err
+			}
+			client.SetWorkspace(workspaceScope(cfg))
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return tui.Run(cmd.Context(), client)
+		},
+	}
+
+	cmd.Flags().StringVar(&accessKey, "access-key", "", accessKeyUsage)
+
+	return cmd
+}
@@ -1,11 +1,14 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/spf13/cobra"
 
 	"github.com/EnSync-engine/CLI/app/api"
+	"github.com/EnSync-engine/CLI/app/completion"
+	"github.com/EnSync-engine/CLI/app/domain"
 )
 
 func newWorkspaceCmd(client *api.Client) *cobra.Command {
@@ -14,22 +17,32 @@ func newWorkspaceCmd(client *api.Client) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "workspace",
 		Short: "Manage workspaces",
-		Long:  "Commands for listing and creating workspaces.",
+		Long: `Commands for listing, creating, renaming, moving, and deleting workspaces.
+
+Workspaces can be referenced by ID or by path (e.g. "gms/urbanhero").`,
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-			if accessKey == "" {
-				return fmt.Errorf("--access-key is required")
+			if _, err := setupClient(client); err != nil {
+				return err
+			}
+			if _, err := authenticate(client, accessKey); err != nil {
+				return err
 			}
-			client.SetAccessKey(accessKey)
+			// Workspace commands address workspaces directly and are never scoped.
+			client.SetWorkspace("")
 			return nil
 		},
 	}
 
-	cmd.PersistentFlags().StringVar(&accessKey, "access-key", "", "access key for API authentication (required)")
-	_ = cmd.MarkPersistentFlagRequired("access-key")
+	cmd.PersistentFlags().StringVar(&accessKey, "access-key", "", accessKeyUsage)
 
 	cmd.AddCommand(
 		newWorkspaceListCmd(client),
+		newWorkspaceGetCmd(client),
 		newWorkspaceCreateCmd(client),
+		newWorkspaceRenameCmd(client),
+		newWorkspaceMoveCmd(client),
+		newWorkspaceDeleteCmd(client),
+		newWorkspaceTreeCmd(client),
 	)
 
 	return cmd
@@ -71,14 +84,44 @@ func newWorkspaceListCmd(client *api.Client) *cobra.Command {
 	return cmd
 }
 
+func newWorkspaceGetCmd(client *api.Client) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "get [id|path]",
+		Short:             "Get a workspace by ID or path",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeResource(client, completion.Workspaces),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			workspace, err := resolveWorkspace(cmd.Context(), client, args[0])
+			if err != nil {
+				return err
+			}
+			return printJSON(cmd.OutOrStdout(), workspace)
+		},
+	}
+
+	return cmd
+}
+
 func newWorkspaceCreateCmd(client *api.Client) *cobra.Command {
-	var name string
+	var (
+		name   string
+		parent string
+	)
 
 	cmd := &cobra.Command{
 		Use:   "create",
 		Short: "Create a new workspace",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if err := client.CreateWorkspace(cmd.Context(), name); err != nil {
+			req := &domain.CreateWorkspaceRequest{Name: name}
+			if parent != "" {
+				parentWorkspace, err := resolveWorkspace(cmd.Context(), client, parent)
+				if err != nil {
+					return err
+				}
+				req.ParentID = parentWorkspace.ID
+			}
+
+			if err := client.CreateWorkspace(cmd.Context(), req); err != nil {
 				return err
 			}
 
@@ -88,7 +131,157 @@ func newWorkspaceCreateCmd(client *api.Client) *cobra.Command {
 	}
 
 	cmd.Flags().StringVar(&name, "name", "", "workspace name (required)")
+	cmd.Flags().StringVar(&parent, "parent", "", "parent workspace ID or path")
 	_ = cmd.MarkFlagRequired("name")
+	_ = cmd.RegisterFlagCompletionFunc("parent", completeResourceFlag(client, completion.Workspaces))
+
+	return cmd
+}
+
+func newWorkspaceRenameCmd(client *api.Client) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "rename [id|path] [new-name]",
+		Short:             "Rename a workspace",
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeResource(client, completion.Workspaces),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			workspace, err := resolveWorkspace(cmd.Context(), client, args[0])
+			if err != nil {
+				return err
+			}
+
+			req := &domain.UpdateWorkspaceRequest{Name: args[1]}
+			if err := client.UpdateWorkspace(cmd.Context(), workspace.ID, req); err != nil {
+				return err
+			}
+
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Workspace %q renamed to %q\n", workspace.Name, args[1])
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func newWorkspaceMoveCmd(client *api.Client) *cobra.Command {
+	var (
+		parent string
+		toRoot bool
+	)
+
+	cmd := &cobra.Command{
+		Use:               "move [id|path]",
+		Short:             "Move a workspace under a new parent",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeResource(client, completion.Workspaces),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			workspace, err := resolveWorkspace(cmd.Context(), client, args[0])
+			if err != nil {
+				return err
+			}
+
+			var parentID, destination string
+			if toRoot {
+				destination = "the root"
+			} else {
+				parentWorkspace, err := resolveWorkspace(cmd.Context(), client, parent)
+				if err != nil {
+					return err
+				}
+				parentID = parentWorkspace.ID
+				destination = fmt.Sprintf("%q", displayPath(parentWorkspace))
+			}
+
+			if err := client.MoveWorkspace(cmd.Context(), workspace.ID, parentID); err != nil {
+				return err
+			}
+
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Workspace %q moved to %s\n", displayPath(workspace), destination)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&parent, "parent", "", "new parent workspace ID or path")
+	cmd.Flags().BoolVar(&toRoot, "root", false, "move the workspace to the top level")
+	cmd.MarkFlagsMutuallyExclusive("parent", "root")
+	cmd.MarkFlagsOneRequired("parent", "root")
+	_ = cmd.RegisterFlagCompletionFunc("parent", completeResourceFlag(client, completion.Workspaces))
 
 	return cmd
 }
+
+func newWorkspaceDeleteCmd(client *api.Client) *cobra.Command {
+	var (
+		recursive bool
+		force     bool
+	)
+
+	cmd := &cobra.Command{
+		Use:               "delete [id|path]",
+		Short:             "Delete a workspace",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeResource(client, completion.Workspaces),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			workspace, err := resolveWorkspace(cmd.Context(), client, args[0])
+			if err != nil {
+				return err
+			}
+
+			if !force {
+				question := fmt.Sprintf("Delete workspace %q?", displayPath(workspace))
+				if recursive {
+					question = fmt.Sprintf("Delete workspace %q and everything under it?", displayPath(workspace))
+				}
+				ok, err := confirm(cmd, question)
+				if err != nil {
+					return err
+				}
+				if !ok {
+					_, _ = fmt.Fprintln(cmd.OutOrStdout(), "Aborted")
+					return nil
+				}
+			}
+
+			if err := client.DeleteWorkspace(cmd.Context(), workspace.ID, recursive); err != nil {
+				return err
+			}
+
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Workspace %q deleted successfully\n", displayPath(workspace))
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&recursive, "recursive", "r", false, "also delete child workspaces")
+	cmd.Flags().BoolVarP(&force, "force", "f", false, "delete without asking for confirmation")
+
+	return cmd
+}
+
+// resolveWorkspace looks up a workspace by ID, falling back to a path match
+// across all workspaces.
+func resolveWorkspace(ctx context.Context, client api.WorkspaceService, idOrPath string) (*domain.Workspace, error) {
+	workspace, err := client.GetWorkspace(ctx, idOrPath)
+	if err == nil {
+		return workspace, nil
+	}
+	if !api.IsNotFound(err) {
+		return nil, err
+	}
+
+	workspaces, err := listAllWorkspaces(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	if found := domain.FindByPath(workspaces, idOrPath); found != nil {
+		return found, nil
+	}
+
+	return nil, fmt.Errorf("workspace %q not found", idOrPath)
+}
+
+func displayPath(workspace *domain.Workspace) string {
+	if workspace.Path != "" {
+		return workspace.Path
+	}
+	return workspace.Name
+}
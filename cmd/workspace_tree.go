@@ -0,0 +1,243 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/EnSync-engine/CLI/app/api"
+	"github.com/EnSync-engine/CLI/app/completion"
+	"github.com/EnSync-engine/CLI/app/domain"
+)
+
+const (
+	treeFormatText    = "text"
+	treeFormatDot     = "dot"
+	treeFormatMermaid = "mermaid"
+)
+
+// workspaceCounts holds the number of events and access keys per workspace
+// path. Only the events directly in a workspace count towards it.
+type workspaceCounts struct {
+	events map[string]int
+	keys   map[string]int
+}
+
+func newWorkspaceTreeCmd(client *api.Client) *cobra.Command {
+	var (
+		root     string
+		depth    int
+		format   string
+		noCounts bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "tree",
+		Short: "Show the workspace hierarchy as a tree",
+		Long: `Show the workspace hierarchy as an ASCII tree, a Graphviz digraph
+(--format dot) or a Mermaid flowchart (--format mermaid).
+
+Each workspace is annotated with the number of events directly in it, not
+counting those of its child workspaces, and the number of access keys with
+permissions on those events.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			switch format {
+			case treeFormatText, treeFormatDot, treeFormatMermaid:
+			default:
+				return fmt.Errorf("unknown format %q: use text, dot or mermaid", format)
+			}
+
+			workspaces, err := listAllWorkspaces(cmd.Context(), client)
+			if err != nil {
+				return err
+			}
+
+			roots := domain.BuildWorkspaceTree(workspaces)
+			if root != "" {
+				found := domain.FindByPath(roots, root)
+				if found == nil {
+					return fmt.Errorf("workspace %q not found", root)
+				}
+				roots = []*domain.Workspace{found}
+			}
+
+			var counts *workspaceCounts
+			if !noCounts {
+				counts, err = countWorkspaceResources(cmd.Context(), client)
+				if err != nil {
+					return err
+				}
+			}
+
+			out := cmd.OutOrStdout()
+			switch format {
+			case treeFormatDot:
+				writeWorkspaceDot(out, roots, depth, counts)
+			case treeFormatMermaid:
+				writeWorkspaceMermaid(out, roots, depth, counts)
+			default:
+				writeWorkspaceText(out, roots, depth, counts)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&root, "root", "", "only show the subtree under this workspace path")
+	cmd.Flags().IntVar(&depth, "depth", 0, "maximum depth to show (0 for unlimited)")
+	cmd.Flags().StringVar(&format, "format", treeFormatText, "output format (text, dot or mermaid)")
+	cmd.Flags().BoolVar(&noCounts, "no-counts", false, "skip counting events and access keys per workspace")
+	_ = cmd.RegisterFlagCompletionFunc("root", completeResourceFlag(client, completion.Workspaces))
+	_ = cmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions([]string{treeFormatText, treeFormatDot, treeFormatMermaid}, cobra.ShellCompDirectiveNoFileComp))
+
+	return cmd
+}
+
+func countWorkspaceResources(ctx context.Context, client api.APIClient) (*workspaceCounts, error) {
+	events, err := listAllEvents(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := listAllAccessKeys(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := &workspaceCounts{
+		events: make(map[string]int),
+		keys:   make(map[string]int),
+	}
+
+	eventWorkspace := make(map[string]string, len(events))
+	for _, event := range events {
+		path := event.WorkspacePath()
+		eventWorkspace[event.Name] = path
+		counts.events[path]++
+	}
+
+	for _, key := range keys {
+		if key.Permissions == nil {
+			continue
+		}
+		seen := make(map[string]bool)
+		for _, name := range append(append([]string{}, key.Permissions.Send...), key.Permissions.Receive...) {
+			path, ok := eventWorkspace[name]
+			if ok && !seen[path] {
+				seen[path] = true
+				counts.keys[path]++
+			}
+		}
+	}
+
+	return counts, nil
+}
+
+func (c *workspaceCounts) label(ws *domain.Workspace) string {
+	if c == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s, %s",
+		plural(c.events[ws.Path], "event", "events"),
+		plural(c.keys[ws.Path], "key", "keys"))
+}
+
+func plural(n int, singular, pluralForm string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, singular)
+	}
+	return fmt.Sprintf("%d %s", n, pluralForm)
+}
+
+// withinDepth reports whether a node at the given level (roots are level 1)
+// should be rendered.
+func withinDepth(level, maxDepth int) bool {
+	return maxDepth <= 0 || level <= maxDepth
+}
+
+func writeWorkspaceText(w io.Writer, roots []*domain.Workspace, maxDepth int, counts *workspaceCounts) {
+	for _, ws := range roots {
+		_, _ = fmt.Fprintln(w, textNodeLabel(ws, counts))
+		writeTextChildren(w, ws.Children, "", 2, maxDepth, counts)
+	}
+}
+
+func writeTextChildren(w io.Writer, children []*domain.Workspace, prefix string, level, maxDepth int, counts *workspaceCounts) {
+	if !withinDepth(level, maxDepth) {
+		return
+	}
+
+	for i, ws := range children {
+		branch, indent := "├── ", "│   "
+		if i == len(children)-1 {
+			branch, indent = "└── ", "    "
+		}
+		_, _ = fmt.Fprintf(w, "%s%s%s\n", prefix, branch, textNodeLabel(ws, counts))
+		writeTextChildren(w, ws.Children, prefix+indent, level+1, maxDepth, counts)
+	}
+}
+
+func textNodeLabel(ws *domain.Workspace, counts *workspaceCounts) string {
+	if label := counts.label(ws); label != "" {
+		return fmt.Sprintf("%s (%s)", ws.Name, label)
+	}
+	return ws.Name
+}
+
+func writeWorkspaceDot(w io.Writer, roots []*domain.Workspace, maxDepth int, counts *workspaceCounts) {
+	_, _ = fmt.Fprintln(w, "digraph workspaces {")
+	_, _ = fmt.Fprintln(w, "  node [shape=box];")
+
+	walkWorkspaces(roots, maxDepth, func(ws, parent *domain.Workspace) {
+		label := ws.Name
+		if extra := counts.label(ws); extra != "" {
+			label += `\n` + extra
+		}
+		_, _ = fmt.Fprintf(w, "  %s [label=%s];\n", dotQuote(ws.ID), dotQuote(label))
+		if parent != nil {
+			_, _ = fmt.Fprintf(w, "  %s -> %s;\n", dotQuote(parent.ID), dotQuote(ws.ID))
+		}
+	})
+
+	_, _ = fmt.Fprintln(w, "}")
+}
+
+func dotQuote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
+
+func writeWorkspaceMermaid(w io.Writer, roots []*domain.Workspace, maxDepth int, counts *workspaceCounts) {
+	_, _ = fmt.Fprintln(w, "graph TD")
+
+	ids := make(map[*domain.Workspace]string)
+	walkWorkspaces(roots, maxDepth, func(ws, parent *domain.Workspace) {
+		id := fmt.Sprintf("ws%d", len(ids))
+		ids[ws] = id
+
+		label := ws.Name
+		if extra := counts.label(ws); extra != "" {
+			label += "<br/>" + extra
+		}
+		_, _ = fmt.Fprintf(w, "  %s[\"%s\"]\n", id, strings.ReplaceAll(label, `"`, "#quot;"))
+		if parent != nil {
+			_, _ = fmt.Fprintf(w, "  %s --> %s\n", ids[parent], id)
+		}
+	})
+}
+
+// walkWorkspaces visits every workspace up to maxDepth in depth-first
+// order, passing its parent (nil for roots).
+func walkWorkspaces(workspaces []*domain.Workspace, maxDepth int, visit func(ws, parent *domain.Workspace)) {
+	var walk func(nodes []*domain.Workspace, parent *domain.Workspace, level int)
+	walk = func(nodes []*domain.Workspace, parent *domain.Workspace, level int) {
+		if !withinDepth(level, maxDepth) {
+			return
+		}
+		for _, ws := range nodes {
+			visit(ws, parent)
+			walk(ws.Children, ws, level+1)
+		}
+	}
+	walk(workspaces, nil, 1)
+}
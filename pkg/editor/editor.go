@@ -0,0 +1,66 @@
+package editor
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// Name returns the user's preferred editor from $VISUAL or $EDITOR,
+// falling back to a platform default.
+func Name() string {
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if editor := strings.TrimSpace(os.Getenv(env)); editor != "" {
+			return editor
+		}
+	}
+	if runtime.GOOS == "windows" {
+		return "notepad"
+	}
+	return "vi"
+}
+
+// Command returns a command that opens path in the user's editor. Editor
+// settings with arguments, such as "code --wait", are split on whitespace.
+func Command(path string) *exec.Cmd {
+	parts := strings.Fields(Name())
+	args := append(parts[1:], path)
+
+	cmd := exec.Command(parts[0], args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd
+}
+
+// Edit writes content to a temporary file named after pattern (see
+// os.CreateTemp), opens it in the user's editor and returns the saved
+// content once the editor exits.
+func Edit(content []byte, pattern string) ([]byte, error) {
+	file, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return nil, fmt.Errorf("create temp file: %w", err)
+	}
+	path := file.Name()
+	defer func() { _ = os.Remove(path) }()
+
+	if _, err := file.Write(content); err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("write temp file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return nil, fmt.Errorf("write temp file: %w", err)
+	}
+
+	if err := Command(path).Run(); err != nil {
+		return nil, fmt.Errorf("run editor %q: %w", Name(), err)
+	}
+
+	edited, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read edited file: %w", err)
+	}
+	return edited, nil
+}
@@ -0,0 +1,177 @@
+// Package shellwords parses the lines typed into the interactive shell.
+package shellwords
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// SplitPipeline splits a shell line on "|" characters outside quotes.
+func SplitPipeline(line string) []string {
+	var (
+		segments []string
+		current  strings.Builder
+		quote    rune
+		escaped  bool
+	)
+
+	for _, r := range line {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '|':
+			segments = append(segments, strings.TrimSpace(current.String()))
+			current.Reset()
+			continue
+		}
+		current.WriteRune(r)
+	}
+
+	return append(segments, strings.TrimSpace(current.String()))
+}
+
+// Tokenize splits a command into arguments, honoring single quotes,
+// double quotes and backslash escapes. Variables ($name, ${name} and
+// JSON paths such as $last.results.0.name) are expanded through lookup
+// everywhere except inside single quotes.
+func Tokenize(s string, lookup func(string) (string, error)) ([]string, error) {
+	var (
+		tokens  []string
+		current strings.Builder
+		inToken bool
+		quote   rune
+	)
+
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+
+		switch {
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\\' && i+1 < len(runes):
+			i++
+			current.WriteRune(runes[i])
+			inToken = true
+		case quote == '"' && r == '"':
+			quote = 0
+		case quote == 0 && (r == '\'' || r == '"'):
+			quote = r
+			inToken = true
+		case r == '$':
+			name, next := scanVariable(runes, i+1)
+			if name == "" {
+				current.WriteRune(r)
+				inToken = true
+				continue
+			}
+			value, err := lookup(name)
+			if err != nil {
+				return nil, err
+			}
+			current.WriteString(value)
+			inToken = true
+			i = next - 1
+		case quote == 0 && unicode.IsSpace(r):
+			if inToken {
+				tokens = append(tokens, current.String())
+				current.Reset()
+				inToken = false
+			}
+		default:
+			current.WriteRune(r)
+			inToken = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if inToken {
+		tokens = append(tokens, current.String())
+	}
+	return tokens, nil
+}
+
+// scanVariable reads a variable reference starting at runes[start] and
+// returns its name and the index just past it.
+func scanVariable(runes []rune, start int) (string, int) {
+	if start < len(runes) && runes[start] == '{' {
+		for end := start + 1; end < len(runes); end++ {
+			if runes[end] == '}' {
+				return string(runes[start+1 : end]), end + 1
+			}
+		}
+		return "", start
+	}
+
+	end := start
+	for end < len(runes) && isVariableRune(runes[end]) {
+		end++
+	}
+	// A trailing dot ends a sentence rather than starting a path segment.
+	for end > start && runes[end-1] == '.' {
+		end--
+	}
+	return string(runes[start:end]), end
+}
+
+func isVariableRune(r rune) bool {
+	return r == '_' || r == '.' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// LookupPath resolves a dotted path such as "results.0.name" in a JSON
+// document. Strings are returned unquoted; other values as compact JSON.
+func LookupPath(document, path string) (string, error) {
+	if path == "" {
+		return strings.TrimSpace(document), nil
+	}
+
+	var value any
+	if err := json.Unmarshal([]byte(document), &value); err != nil {
+		return "", fmt.Errorf("value is not JSON, cannot resolve %q", path)
+	}
+
+	for _, segment := range strings.Split(path, ".") {
+		switch current := value.(type) {
+		case map[string]any:
+			next, ok := current[segment]
+			if !ok {
+				return "", fmt.Errorf("no field %q", segment)
+			}
+			value = next
+		case []any:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(current) {
+				return "", fmt.Errorf("invalid index %q", segment)
+			}
+			value = current[index]
+		default:
+			return "", fmt.Errorf("cannot index %q into a scalar", segment)
+		}
+	}
+
+	if str, ok := value.(string); ok {
+		return str, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,173 @@
+package integration
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/EnSync-engine/CLI/app/api"
+	"github.com/EnSync-engine/CLI/app/bundle"
+	"github.com/EnSync-engine/CLI/app/domain"
+)
+
+func TestBundle(t *testing.T) {
+	const passphrase = "correct horse battery"
+	secret := []byte(`[{"accessKey":"ak_1","service_key_pair":{"public_key":"pk_1","private_key":"sk_1"}}]`)
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		sealed, err := bundle.Seal(secret, passphrase)
+		require.NoError(t, err)
+		assert.NotContains(t, string(sealed), "sk_1")
+
+		opened, err := bundle.Open(sealed, passphrase)
+
+		require.NoError(t, err)
+		assert.Equal(t, secret, opened)
+	})
+
+	t.Run("WrongPassphrase", func(t *testing.T) {
+		sealed, err := bundle.Seal(secret, passphrase)
+		require.NoError(t, err)
+
+		_, err = bundle.Open(sealed, "incorrect horse")
+
+		assert.ErrorIs(t, err, bundle.ErrWrongPassphrase)
+	})
+
+	t.Run("Tampered", func(t *testing.T) {
+		sealed, err := bundle.Seal(secret, passphrase)
+		require.NoError(t, err)
+		var env map[string]any
+		require.NoError(t, json.Unmarshal(sealed, &env))
+		env["format"] = "ensync-bundle-v0"
+		tampered, err := json.Marshal(env)
+		require.NoError(t, err)
+
+		_, err = bundle.Open(tampered, passphrase)
+
+		assert.ErrorContains(t, err, "unsupported bundle format")
+	})
+
+	t.Run("ShortPassphrase", func(t *testing.T) {
+		_, err := bundle.Seal(secret, "short")
+
+		assert.ErrorIs(t, err, bundle.ErrShortPassphrase)
+	})
+
+	t.Run("File", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "rotated.bundle")
+		require.NoError(t, bundle.WriteFile(path, []byte("first"), passphrase))
+		require.NoError(t, bundle.WriteFile(path, secret, passphrase))
+
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		opened, err := bundle.ReadFile(path, passphrase)
+
+		require.NoError(t, err)
+		assert.Equal(t, secret, opened)
+		if info.Mode().Perm()&0o077 != 0 {
+			t.Errorf("bundle is readable by others: %v", info.Mode().Perm())
+		}
+		_, err = os.Stat(path + ".tmp")
+		assert.True(t, os.IsNotExist(err), "the temporary file is renamed into place")
+	})
+
+	t.Run("Sealer", func(t *testing.T) {
+		sealer, err := bundle.NewSealer(passphrase)
+		require.NoError(t, err)
+
+		first, err := sealer.Seal([]byte("first"))
+		require.NoError(t, err)
+		second, err := sealer.Seal(secret)
+		require.NoError(t, err)
+
+		var firstEnv, secondEnv map[string]any
+		require.NoError(t, json.Unmarshal(first, &firstEnv))
+		require.NoError(t, json.Unmarshal(second, &secondEnv))
+		assert.Equal(t, firstEnv["salt"], secondEnv["salt"], "the key is derived once")
+		assert.NotEqual(t, firstEnv["nonce"], secondEnv["nonce"], "every bundle has its own nonce")
+		opened, err := bundle.Open(second, passphrase)
+		require.NoError(t, err)
+		assert.Equal(t, secret, opened)
+	})
+}
+
+// TestDeriveKey checks the key derivation against the PBKDF2-HMAC-SHA256
+// test vectors of RFC 7914 section 11, truncated to the key size.
+func TestDeriveKey(t *testing.T) {
+	tests := []struct {
+		passphrase string
+		salt       string
+		iterations int
+		want       string
+	}{
+		{passphrase: "passwd", salt: "salt", iterations: 1, want: "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc"},
+		{passphrase: "Password", salt: "NaCl", iterations: 80000, want: "4ddcd8f60b98be21830cee5ef22701f9641a4418d04c0414aeff08876b34ab56"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.passphrase, func(t *testing.T) {
+			key := bundle.DeriveKey(tt.passphrase, []byte(tt.salt), tt.iterations)
+
+			assert.Equal(t, tt.want, hex.EncodeToString(key))
+		})
+	}
+}
+
+func TestAccessKeyExpiry(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	in := func(d time.Duration) *time.Time {
+		at := now.Add(d)
+		return &at
+	}
+
+	t.Run("ExpiresBefore", func(t *testing.T) {
+		deadline := now.Add(14 * 24 * time.Hour)
+
+		assert.True(t, (&domain.AccessKeyPermissions{ExpiresAt: in(-time.Hour)}).ExpiresBefore(deadline), "already expired")
+		assert.True(t, (&domain.AccessKeyPermissions{ExpiresAt: in(7 * 24 * time.Hour)}).ExpiresBefore(deadline))
+		assert.False(t, (&domain.AccessKeyPermissions{ExpiresAt: in(30 * 24 * time.Hour)}).ExpiresBefore(deadline))
+		assert.False(t, (&domain.AccessKeyPermissions{}).ExpiresBefore(deadline), "keys without an expiry never expire")
+	})
+
+	t.Run("KeyPairAge", func(t *testing.T) {
+		created := &domain.AccessKeyPermissions{CreatedAt: now.Add(-100 * 24 * time.Hour)}
+		rotated := &domain.AccessKeyPermissions{CreatedAt: created.CreatedAt, RotatedAt: in(-10 * 24 * time.Hour)}
+
+		assert.Equal(t, 100*24*time.Hour, created.KeyPairAge(now))
+		assert.Equal(t, 10*24*time.Hour, rotated.KeyPairAge(now))
+	})
+
+	t.Run("SentOnCreate", func(t *testing.T) {
+		var got domain.CreateAccessKeyRequest
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+			w.WriteHeader(http.StatusCreated)
+			writeJSON(w, domain.AccessKey{AccessKey: "ak_1", ExpiresAt: got.ExpiresAt})
+		}))
+		defer server.Close()
+		client := api.NewClient(server.URL)
+		client.SetAccessKey(testAccessKey)
+
+		created, err := client.CreateAccessKey(context.Background(), &domain.CreateAccessKeyRequest{
+			Type:      "SERVICE",
+			Name:      "ci",
+			ExpiresAt: in(90 * 24 * time.Hour),
+		})
+
+		require.NoError(t, err)
+		require.NotNil(t, got.ExpiresAt)
+		assert.True(t, got.ExpiresAt.Equal(*in(90 * 24 * time.Hour)))
+		require.NotNil(t, created.ExpiresAt)
+		assert.True(t, created.ExpiresAt.Equal(*got.ExpiresAt))
+	})
+}
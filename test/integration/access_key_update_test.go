@@ -0,0 +1,88 @@
+package integration
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/EnSync-engine/CLI/app/api"
+	"github.com/EnSync-engine/CLI/app/domain"
+)
+
+func TestUpdateAccessKey(t *testing.T) {
+	var (
+		method, path, contentType string
+		body                      []byte
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, path, contentType = r.Method, r.URL.Path, r.Header.Get("Content-Type")
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	client := api.NewClient(server.URL)
+	client.SetAccessKey(testAccessKey)
+	ctx := context.Background()
+
+	t.Run("Metadata", func(t *testing.T) {
+		name, description, team := "billing", "", "payments"
+
+		err := client.UpdateAccessKey(ctx, "key-1", &domain.UpdateAccessKeyRequest{
+			Name:        &name,
+			Description: &description,
+			Labels:      map[string]*string{"team": &team, "env": nil},
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, http.MethodPatch, method)
+		assert.Equal(t, "/access-key/key-1", path)
+		assert.Equal(t, "application/merge-patch+json", contentType)
+		assert.JSONEq(t, `{"name":"billing","description":"","labels":{"team":"payments","env":null}}`, string(body),
+			"an empty description clears it and a nil label removes it")
+	})
+
+	t.Run("State", func(t *testing.T) {
+		state := domain.AccessKeyStateDisabled
+
+		err := client.UpdateAccessKey(ctx, "key-1", &domain.UpdateAccessKeyRequest{State: &state})
+
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"state":"DISABLED"}`, string(body))
+	})
+}
+
+func TestAccessKeyState(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+
+	for name, tc := range map[string]struct {
+		key  domain.AccessKeyPermissions
+		want string
+	}{
+		"NoState":         {key: domain.AccessKeyPermissions{}, want: domain.AccessKeyStateActive},
+		"Active":          {key: domain.AccessKeyPermissions{State: "active", ExpiresAt: &future}, want: domain.AccessKeyStateActive},
+		"Disabled":        {key: domain.AccessKeyPermissions{State: domain.AccessKeyStateDisabled}, want: domain.AccessKeyStateDisabled},
+		"Expired":         {key: domain.AccessKeyPermissions{ExpiresAt: &past}, want: domain.AccessKeyStateExpired},
+		"DisabledExpired": {key: domain.AccessKeyPermissions{State: domain.AccessKeyStateDisabled, ExpiresAt: &past}, want: domain.AccessKeyStateExpired},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.key.EffectiveState(now))
+		})
+	}
+
+	t.Run("HasLabels", func(t *testing.T) {
+		key := &domain.AccessKeyPermissions{Labels: map[string]string{"team": "billing", "env": "prod"}}
+
+		assert.True(t, key.HasLabels(nil))
+		assert.True(t, key.HasLabels(map[string]string{"team": "billing"}))
+		assert.True(t, key.HasLabels(map[string]string{"team": "billing", "env": "prod"}))
+		assert.False(t, key.HasLabels(map[string]string{"team": "payments"}))
+		assert.False(t, key.HasLabels(map[string]string{"owner": ""}), "a missing label does not match an empty value")
+	})
+}
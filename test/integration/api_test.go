@@ -24,6 +24,8 @@ var (
 	eventNamePattern     = regexp.MustCompile(`^/event/[\w-]+$`)
 	accessKeyIDPattern   = regexp.MustCompile(`^/access-key/[\w-]+$`)
 	accessKeyPermPattern = regexp.MustCompile(`^/access-key/[\w-]+/permissions$`)
+	workspaceIDPattern   = regexp.MustCompile(`^/workspace/[\w-]+$`)
+	workspaceMovePattern = regexp.MustCompile(`^/workspace/[\w-]+/move$`)
 )
 
 func TestClient(t *testing.T) {
@@ -68,6 +70,28 @@ func testEventOperations(t *testing.T, ctx context.Context, client *api.Client)
 		assert.Equal(t, "test-event", event.Name)
 	})
 
+	t.Run("GetByID", func(t *testing.T) {
+		event, err := client.GetEventByID(ctx, "event-1")
+
+		require.NoError(t, err)
+		require.NotNil(t, event)
+		assert.Equal(t, "event-1", event.ID)
+	})
+
+	t.Run("GetByIDNotFound", func(t *testing.T) {
+		_, err := client.GetEventByID(ctx, "missing-id")
+
+		require.Error(t, err)
+		assert.True(t, api.IsNotFound(err))
+	})
+
+	t.Run("GetByNameNotFound", func(t *testing.T) {
+		_, err := client.GetEventByName(ctx, "missing-event")
+
+		require.Error(t, err)
+		assert.True(t, api.IsNotFound(err))
+	})
+
 	t.Run("Create", func(t *testing.T) {
 		event := &domain.Event{
 			Name:    "new-event",
@@ -90,6 +114,21 @@ func testEventOperations(t *testing.T, ctx context.Context, client *api.Client)
 
 		require.NoError(t, err)
 	})
+
+	t.Run("Patch", func(t *testing.T) {
+		name := "renamed-event"
+		patch := &domain.EventPatch{Name: &name}
+
+		err := client.PatchEvent(ctx, "event-123", patch)
+
+		require.NoError(t, err)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		err := client.DeleteEvent(ctx, "event-123")
+
+		require.NoError(t, err)
+	})
 }
 
 func testAccessKeyOperations(t *testing.T, ctx context.Context, client *api.Client) {
@@ -175,7 +214,33 @@ func testWorkspaceOperations(t *testing.T, ctx context.Context, client *api.Clie
 	})
 
 	t.Run("Create", func(t *testing.T) {
-		err := client.CreateWorkspace(ctx, "new-workspace")
+		err := client.CreateWorkspace(ctx, &domain.CreateWorkspaceRequest{Name: "new-workspace", ParentID: "ws-1"})
+
+		require.NoError(t, err)
+	})
+
+	t.Run("Get", func(t *testing.T) {
+		workspace, err := client.GetWorkspace(ctx, "ws-1")
+
+		require.NoError(t, err)
+		require.NotNil(t, workspace)
+		assert.Equal(t, "gms", workspace.Path)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		err := client.UpdateWorkspace(ctx, "ws-1", &domain.UpdateWorkspaceRequest{Name: "renamed"})
+
+		require.NoError(t, err)
+	})
+
+	t.Run("Move", func(t *testing.T) {
+		err := client.MoveWorkspace(ctx, "ws-2", "ws-1")
+
+		require.NoError(t, err)
+	})
+
+	t.Run("DeleteRecursive", func(t *testing.T) {
+		err := client.DeleteWorkspace(ctx, "ws-1", true)
 
 		require.NoError(t, err)
 	})
@@ -191,10 +256,16 @@ func newMockServer(t *testing.T) *httptest.Server {
 			handleListEvents(w)
 		case r.Method == http.MethodPost && r.URL.Path == "/event":
 			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodGet && r.URL.Path == "/event/missing-event":
+			w.WriteHeader(http.StatusNotFound)
 		case r.Method == http.MethodGet && eventNamePattern.MatchString(r.URL.Path):
 			handleGetEvent(w)
 		case r.Method == http.MethodPut && eventNamePattern.MatchString(r.URL.Path):
 			w.WriteHeader(http.StatusOK)
+		case r.Method == http.MethodPatch && eventNamePattern.MatchString(r.URL.Path):
+			handlePatchEvent(t, w, r)
+		case r.Method == http.MethodDelete && eventNamePattern.MatchString(r.URL.Path):
+			w.WriteHeader(http.StatusNoContent)
 
 		case r.Method == http.MethodGet && r.URL.Path == "/access-key":
 			handleListAccessKeys(w)
@@ -216,6 +287,16 @@ func newMockServer(t *testing.T) *httptest.Server {
 		case r.Method == http.MethodPost && r.URL.Path == "/workspace":
 			w.WriteHeader(http.StatusOK)
 			writeJSON(w, map[string]any{})
+		case r.Method == http.MethodGet && workspaceIDPattern.MatchString(r.URL.Path):
+			writeJSON(w, domain.Workspace{ID: "ws-1", Name: "gms", Path: "gms"})
+		case r.Method == http.MethodPatch && workspaceIDPattern.MatchString(r.URL.Path):
+			assert.Equal(t, "application/merge-patch+json", r.Header.Get("Content-Type"))
+			w.WriteHeader(http.StatusOK)
+		case r.Method == http.MethodPost && workspaceMovePattern.MatchString(r.URL.Path):
+			w.WriteHeader(http.StatusOK)
+		case r.Method == http.MethodDelete && workspaceIDPattern.MatchString(r.URL.Path):
+			assert.Equal(t, "true", r.URL.Query().Get("recursive"))
+			w.WriteHeader(http.StatusNoContent)
 
 		default:
 			w.WriteHeader(http.StatusNotFound)
@@ -241,6 +322,16 @@ func handleGetEvent(w http.ResponseWriter) {
 	})
 }
 
+func handlePatchEvent(t *testing.T, w http.ResponseWriter, r *http.Request) {
+	assert.Equal(t, "application/merge-patch+json", r.Header.Get("Content-Type"))
+
+	var patch map[string]any
+	assert.NoError(t, json.NewDecoder(r.Body).Decode(&patch))
+	assert.Equal(t, map[string]any{"name": "renamed-event"}, patch)
+
+	w.WriteHeader(http.StatusOK)
+}
+
 func handleListAccessKeys(w http.ResponseWriter) {
 	writeJSON(w, domain.AccessKeyList{
 		ResultsLength: 2,
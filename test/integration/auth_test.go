@@ -0,0 +1,249 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/EnSync-engine/CLI/app/api"
+	"github.com/EnSync-engine/CLI/app/domain"
+)
+
+// keyServer accepts requests carrying the access key in *valid.
+func keyServer(t *testing.T, valid *atomic.Value) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(headerAccess) != valid.Load().(string) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		writeJSON(w, domain.Event{ID: "event-1", Name: "stripe"})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestStaticKeyAuthentication(t *testing.T) {
+	var valid atomic.Value
+	valid.Store(testAccessKey)
+	server := keyServer(t, &valid)
+
+	client := api.NewClient(server.URL)
+	client.SetAuthenticator(api.StaticKey(testAccessKey))
+
+	_, err := client.GetEventByName(context.Background(), "stripe")
+
+	require.NoError(t, err)
+	assert.Equal(t, "access key ****-key", api.StaticKey(testAccessKey).String())
+}
+
+func TestMaskSecret(t *testing.T) {
+	tests := []struct {
+		secret string
+		want   string
+	}{
+		{secret: "", want: "****"},
+		{secret: "abcd", want: "****"},
+		{secret: "ak_live_1234", want: "****1234"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, api.MaskSecret(tt.secret), "secret %q", tt.secret)
+	}
+}
+
+func TestKeyFileAuthentication(t *testing.T) {
+	var valid atomic.Value
+	valid.Store("first-key")
+	server := keyServer(t, &valid)
+	ctx := context.Background()
+
+	path := filepath.Join(t.TempDir(), "key")
+	require.NoError(t, os.WriteFile(path, []byte("first-key\n"), 0o600))
+	client := api.NewClient(server.URL)
+	client.SetAuthenticator(api.NewKeyFileAuthenticator(path))
+
+	_, err := client.GetEventByName(ctx, "stripe")
+	require.NoError(t, err)
+
+	t.Run("RereadsRotatedKey", func(t *testing.T) {
+		valid.Store("second-key")
+		require.NoError(t, os.WriteFile(path, []byte("second-key"), 0o600))
+
+		_, err := client.GetEventByName(ctx, "stripe")
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("MissingFile", func(t *testing.T) {
+		client := api.NewClient(server.URL)
+		client.SetAuthenticator(api.NewKeyFileAuthenticator(filepath.Join(t.TempDir(), "missing")))
+
+		_, err := client.GetEventByName(ctx, "stripe")
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "read access key file")
+	})
+}
+
+func TestKeyCommandAuthentication(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the commands below need a POSIX shell")
+	}
+
+	var valid atomic.Value
+	valid.Store(testAccessKey)
+	server := keyServer(t, &valid)
+	ctx := context.Background()
+
+	t.Run("FirstLineIsKey", func(t *testing.T) {
+		client := api.NewClient(server.URL)
+		client.SetAuthenticator(api.NewKeyCommandAuthenticator(`printf '` + testAccessKey + `\nlogin: ci\n'`))
+
+		_, err := client.GetEventByName(ctx, "stripe")
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("FailingCommand", func(t *testing.T) {
+		client := api.NewClient(server.URL)
+		client.SetAuthenticator(api.NewKeyCommandAuthenticator("echo 'ensync is not in the password store' >&2; exit 1"))
+
+		_, err := client.GetEventByName(ctx, "stripe")
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not in the password store")
+	})
+
+	t.Run("EmptyOutput", func(t *testing.T) {
+		client := api.NewClient(server.URL)
+		client.SetAuthenticator(api.NewKeyCommandAuthenticator("true"))
+
+		_, err := client.GetEventByName(ctx, "stripe")
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "access key is empty")
+	})
+}
+
+func TestOAuth2Authentication(t *testing.T) {
+	var (
+		tokenRequests atomic.Int32
+		validToken    atomic.Value
+		expiresIn     atomic.Int64
+	)
+	validToken.Store("token-1")
+	expiresIn.Store(3600)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /oauth/token", func(w http.ResponseWriter, r *http.Request) {
+		id, secret, _ := r.BasicAuth()
+		if id != "ci" || secret != "s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			writeJSON(w, map[string]string{"error": "invalid_client", "error_description": "unknown client"})
+			return
+		}
+		assert.Equal(t, "client_credentials", r.FormValue("grant_type"))
+		assert.Equal(t, "events:read events:write", r.FormValue("scope"))
+
+		token := fmt.Sprintf("token-%d", tokenRequests.Add(1))
+		writeJSON(w, map[string]any{"access_token": token, "token_type": "Bearer", "expires_in": expiresIn.Load()})
+	})
+	mux.HandleFunc("/event/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+validToken.Load().(string) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		writeJSON(w, domain.Event{ID: "event-1", Name: "stripe"})
+	})
+	// The token endpoint is only trusted through the client's TLS settings.
+	server := httptest.NewTLSServer(mux)
+	defer server.Close()
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	writePEM(t, caFile, "CERTIFICATE", server.Certificate().Raw)
+	tlsConfig, err := api.NewTLSConfig(api.TLSOptions{CAFile: caFile})
+	require.NoError(t, err)
+
+	newClient := func(t *testing.T, secret string) *api.Client {
+		auth, err := api.NewOAuth2Authenticator(api.OAuth2Options{
+			TokenURL:     server.URL + "/oauth/token",
+			ClientID:     "ci",
+			ClientSecret: secret,
+			Scopes:       []string{"events:read", "events:write"},
+		})
+		require.NoError(t, err)
+		client := api.NewClient(server.URL, api.WithTLSConfig(tlsConfig))
+		client.SetAuthenticator(auth)
+		return client
+	}
+	ctx := context.Background()
+
+	t.Run("ReusesToken", func(t *testing.T) {
+		tokenRequests.Store(0)
+		client := newClient(t, "s3cret")
+
+		for range 3 {
+			_, err := client.GetEventByName(ctx, "stripe")
+			require.NoError(t, err)
+		}
+
+		assert.Equal(t, int32(1), tokenRequests.Load())
+	})
+
+	t.Run("RefreshesExpiringToken", func(t *testing.T) {
+		tokenRequests.Store(0)
+		expiresIn.Store(10)
+		defer expiresIn.Store(3600)
+		client := newClient(t, "s3cret")
+
+		validToken.Store("token-1")
+		_, err := client.GetEventByName(ctx, "stripe")
+		require.NoError(t, err)
+		validToken.Store("token-2")
+		_, err = client.GetEventByName(ctx, "stripe")
+		require.NoError(t, err)
+
+		// Tokens expiring within the leeway are replaced before each request.
+		assert.Equal(t, int32(2), tokenRequests.Load())
+	})
+
+	t.Run("RefreshesRejectedToken", func(t *testing.T) {
+		tokenRequests.Store(0)
+		client := newClient(t, "s3cret")
+
+		validToken.Store("token-1")
+		_, err := client.GetEventByName(ctx, "stripe")
+		require.NoError(t, err)
+
+		// The server revokes the token before it expires.
+		validToken.Store("token-2")
+		_, err = client.GetEventByName(ctx, "stripe")
+
+		require.NoError(t, err)
+		assert.Equal(t, int32(2), tokenRequests.Load())
+	})
+
+	t.Run("TokenRequestRejected", func(t *testing.T) {
+		_, err := newClient(t, "wrong").GetEventByName(ctx, "stripe")
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid_client: unknown client")
+	})
+
+	t.Run("InvalidOptions", func(t *testing.T) {
+		_, err := api.NewOAuth2Authenticator(api.OAuth2Options{TokenURL: "ftp://auth.example", ClientID: "ci"})
+		assert.Error(t, err)
+
+		_, err = api.NewOAuth2Authenticator(api.OAuth2Options{TokenURL: server.URL})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,124 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/EnSync-engine/CLI/app/api"
+	"github.com/EnSync-engine/CLI/app/bulk"
+	"github.com/EnSync-engine/CLI/app/domain"
+)
+
+func TestBulkRead(t *testing.T) {
+	t.Run("NDJSON", func(t *testing.T) {
+		input := `{"name":"gms/a","payload":{"k":1}}
+
+{"name":"gms/b","send":["x","y"]}
+`
+		records, err := bulk.Read(strings.NewReader(input), bulk.FormatNDJSON)
+
+		require.NoError(t, err)
+		require.Len(t, records, 2)
+		assert.Equal(t, 3, records[1].Line)
+
+		payload, err := records[0].Object("payload")
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, payload["k"])
+
+		send, err := records[1].List("send")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"x", "y"}, send)
+	})
+
+	t.Run("CSV", func(t *testing.T) {
+		input := "name,payload,send\ngms/a,\"{\"\"k\"\":1}\",x; y\n"
+
+		records, err := bulk.Read(strings.NewReader(input), bulk.FormatCSV)
+
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+		assert.Equal(t, 2, records[0].Line)
+
+		payload, err := records[0].Object("payload")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"k": float64(1)}, payload)
+
+		send, err := records[0].List("send")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"x", "y"}, send)
+	})
+
+	t.Run("InvalidNDJSON", func(t *testing.T) {
+		_, err := bulk.Read(strings.NewReader("{\"name\":\"a\"}\nnot json\n"), bulk.FormatNDJSON)
+
+		assert.ErrorContains(t, err, "line 2")
+	})
+}
+
+func TestBulkRunner(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		created []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event domain.Event
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&event))
+		if strings.HasPrefix(event.Name, "bad") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		created = append(created, event.Name)
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := api.NewClient(server.URL)
+	client.SetAccessKey(testAccessKey)
+
+	records := []bulk.Record{
+		{Line: 1, Fields: map[string]any{"name": "gms/a"}},
+		{Line: 2, Fields: map[string]any{"name": "bad/b"}},
+		{Line: 3, Fields: map[string]any{"name": "gms/c"}},
+	}
+	task := func(ctx context.Context, record bulk.Record) (string, any, error) {
+		name, _ := record.String("name")
+		return name, nil, client.CreateEvent(ctx, &domain.Event{Name: name})
+	}
+
+	t.Run("ContinueOnError", func(t *testing.T) {
+		created = nil
+		runner := &bulk.Runner{Workers: 3, ContinueOnError: true}
+
+		results := runner.Run(context.Background(), records, task)
+
+		require.Len(t, results, 3)
+		assert.Equal(t, bulk.StatusSucceeded, results[0].Status)
+		assert.Equal(t, bulk.StatusFailed, results[1].Status)
+		assert.Equal(t, "bad/b", results[1].Name)
+		assert.NotEmpty(t, results[1].Error)
+		assert.Equal(t, bulk.StatusSucceeded, results[2].Status)
+		assert.ElementsMatch(t, []string{"gms/a", "gms/c"}, created)
+	})
+
+	t.Run("StopOnError", func(t *testing.T) {
+		created = nil
+		runner := &bulk.Runner{Workers: 1}
+
+		results := runner.Run(context.Background(), records, task)
+
+		assert.Equal(t, 1, bulk.Count(results, bulk.StatusSucceeded))
+		assert.Equal(t, 1, bulk.Count(results, bulk.StatusFailed))
+		assert.Equal(t, bulk.StatusSkipped, results[2].Status)
+		assert.Equal(t, []string{"gms/a"}, created)
+	})
+}
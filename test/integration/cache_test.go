@@ -0,0 +1,242 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/EnSync-engine/CLI/app/api"
+	"github.com/EnSync-engine/CLI/app/domain"
+)
+
+func TestResponseCache(t *testing.T) {
+	var (
+		gets        atomic.Int32
+		notModified atomic.Int32
+		version     atomic.Int32
+	)
+	version.Store(1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		etag := fmt.Sprintf(`"v%d"`, version.Load())
+
+		switch r.Method {
+		case http.MethodGet:
+			gets.Add(1)
+			if r.Header.Get("If-None-Match") == etag {
+				notModified.Add(1)
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("ETag", etag)
+			w.Header().Set("Cache-Control", "max-age=60")
+			writeJSON(w, domain.Event{ID: "event-1", Name: "stripe", Payload: map[string]any{"version": version.Load()}})
+		case http.MethodPatch:
+			version.Add(1)
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	newClient := func(ttl time.Duration, dir string) *api.Client {
+		client := api.NewClient(server.URL, api.WithCache(api.NewResponseCache(dir, ttl)))
+		client.SetAccessKey(testAccessKey)
+		return client
+	}
+
+	t.Run("ServesFreshEntries", func(t *testing.T) {
+		gets.Store(0)
+		client := newClient(time.Minute, t.TempDir())
+
+		for range 3 {
+			event, err := client.GetEventByName(ctx, "stripe")
+			require.NoError(t, err)
+			assert.Equal(t, "event-1", event.ID)
+		}
+
+		assert.Equal(t, int32(1), gets.Load())
+	})
+
+	t.Run("RevalidatesWithETag", func(t *testing.T) {
+		gets.Store(0)
+		notModified.Store(0)
+		client := newClient(0, t.TempDir())
+
+		for range 2 {
+			event, err := client.GetEventByName(ctx, "stripe")
+			require.NoError(t, err)
+			assert.Equal(t, "stripe", event.Name)
+		}
+
+		assert.Equal(t, int32(2), gets.Load())
+		assert.Equal(t, int32(1), notModified.Load())
+	})
+
+	t.Run("InvalidatesOnWrite", func(t *testing.T) {
+		gets.Store(0)
+		client := newClient(time.Minute, t.TempDir())
+
+		_, err := client.GetEventByName(ctx, "stripe")
+		require.NoError(t, err)
+
+		name := "stripe"
+		require.NoError(t, client.PatchEvent(ctx, "event-1", &domain.EventPatch{Name: &name}))
+
+		event, err := client.GetEventByName(ctx, "stripe")
+		require.NoError(t, err)
+		assert.Equal(t, int32(2), gets.Load())
+		assert.EqualValues(t, version.Load(), event.Payload["version"])
+	})
+
+	t.Run("SeparatesAccessKeys", func(t *testing.T) {
+		gets.Store(0)
+		dir := t.TempDir()
+		client := newClient(time.Minute, dir)
+
+		_, err := client.GetEventByName(ctx, "stripe")
+		require.NoError(t, err)
+		client.SetAccessKey("another-key")
+		_, err = client.GetEventByName(ctx, "stripe")
+		require.NoError(t, err)
+
+		assert.Equal(t, int32(2), gets.Load())
+	})
+}
+
+func TestResponseCacheFreshness(t *testing.T) {
+	var gets atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gets.Add(1)
+		switch r.URL.Path {
+		case "/event/etag-only":
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("ETag", `"v1"`)
+		case "/access-key/key-1":
+			w.Header().Set("Cache-Control", "max-age=60")
+			writeJSON(w, domain.AccessKeyPermissions{ID: "key-1", ServiceKeyPair: &domain.ServiceKeyPair{PublicKey: "pk_1", PrivateKey: "sk_1"}})
+			return
+		case "/access-key":
+			w.Header().Set("Cache-Control", "max-age=60")
+			writeJSON(w, domain.AccessKeyList{ResultsLength: 1, Results: []*domain.AccessKeyPermissions{{ID: "key-1", Key: "ak_raw_1"}}})
+			return
+		case "/event/max-age":
+			w.Header().Set("Cache-Control", "max-age=60")
+		}
+		writeJSON(w, domain.Event{ID: "event-1", Name: "stripe"})
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	newClient := func(dir string, opts ...api.CacheOption) *api.Client {
+		client := api.NewClient(server.URL, api.WithCache(api.NewResponseCache(dir, time.Minute, opts...)))
+		client.SetAccessKey(testAccessKey)
+		return client
+	}
+	cached := func(t *testing.T, dir string) []string {
+		files, err := filepath.Glob(filepath.Join(dir, "*", "*.json"))
+		require.NoError(t, err)
+		return files
+	}
+	getTwice := func(t *testing.T, get func(ctx context.Context) error) int32 {
+		gets.Store(0)
+		require.NoError(t, get(ctx))
+		require.NoError(t, get(ctx))
+		return gets.Load()
+	}
+
+	t.Run("SkipsUnmarkedResponses", func(t *testing.T) {
+		dir := t.TempDir()
+		client := newClient(dir)
+
+		requests := getTwice(t, func(ctx context.Context) error {
+			_, err := client.GetEventByName(ctx, "unmarked")
+			return err
+		})
+
+		assert.Equal(t, int32(2), requests)
+		assert.Empty(t, cached(t, dir), "responses without max-age or validators are not stored")
+	})
+
+	t.Run("AssumedFreshness", func(t *testing.T) {
+		client := newClient(t.TempDir(), api.WithAssumedFreshness())
+
+		requests := getTwice(t, func(ctx context.Context) error {
+			_, err := client.GetEventByName(ctx, "unmarked")
+			return err
+		})
+
+		assert.Equal(t, int32(1), requests)
+	})
+
+	t.Run("RevalidatesWithoutMaxAge", func(t *testing.T) {
+		dir := t.TempDir()
+		client := newClient(dir)
+
+		requests := getTwice(t, func(ctx context.Context) error {
+			_, err := client.GetEventByName(ctx, "etag-only")
+			return err
+		})
+
+		assert.Equal(t, int32(2), requests)
+		assert.Len(t, cached(t, dir), 1)
+	})
+
+	t.Run("NeverStoresKeyPairs", func(t *testing.T) {
+		dir := t.TempDir()
+		client := newClient(dir, api.WithAssumedFreshness())
+
+		requests := getTwice(t, func(ctx context.Context) error {
+			_, err := client.GetAccessKeyByID(ctx, "key-1")
+			return err
+		})
+
+		assert.Equal(t, int32(2), requests)
+		for _, file := range cached(t, dir) {
+			data, err := os.ReadFile(file)
+			require.NoError(t, err)
+			assert.NotContains(t, string(data), "sk_1")
+		}
+	})
+
+	t.Run("NeverStoresAccessKeys", func(t *testing.T) {
+		dir := t.TempDir()
+		client := newClient(dir, api.WithAssumedFreshness())
+		params := api.DefaultListParams()
+		params.Filter = map[string]string{"accessKey": "ak_raw_1"}
+
+		requests := getTwice(t, func(ctx context.Context) error {
+			_, err := client.ListAccessKeys(ctx, params)
+			return err
+		})
+
+		assert.Equal(t, int32(2), requests)
+		assert.Empty(t, cached(t, dir), "access key lists hold raw keys")
+	})
+
+	t.Run("WithoutCache", func(t *testing.T) {
+		dir := t.TempDir()
+		client := newClient(dir)
+		_, err := client.GetEventByName(ctx, "max-age")
+		require.NoError(t, err)
+
+		requests := getTwice(t, func(ctx context.Context) error {
+			_, err := client.GetEventByName(api.WithoutCache(ctx), "max-age")
+			return err
+		})
+
+		assert.Equal(t, int32(2), requests, "requests bypass fresh entries")
+	})
+}
@@ -0,0 +1,132 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/EnSync-engine/CLI/app/api"
+	"github.com/EnSync-engine/CLI/app/domain"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	var (
+		eventCalls atomic.Int32
+		healthy    atomic.Bool
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/event") {
+			eventCalls.Add(1)
+			if !healthy.Load() {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			writeJSON(w, domain.Event{ID: "event-1", Name: "stripe"})
+			return
+		}
+		writeJSON(w, domain.AccessKeyList{})
+	}))
+	defer server.Close()
+
+	newClient := func(policy api.CircuitBreakerPolicy) *api.Client {
+		retries := api.DefaultRetryPolicy()
+		retries.MaxRetries = 0
+		client := api.NewClient(server.URL, api.WithRetryPolicy(retries), api.WithCircuitBreaker(api.NewCircuitBreaker(policy)))
+		client.SetAccessKey(testAccessKey)
+		return client
+	}
+	ctx := context.Background()
+
+	t.Run("OpensAfterConsecutiveFailures", func(t *testing.T) {
+		eventCalls.Store(0)
+		healthy.Store(false)
+		client := newClient(api.CircuitBreakerPolicy{ConsecutiveFailures: 3, Window: 10, OpenTimeout: time.Minute})
+
+		for range 3 {
+			_, err := client.GetEventByName(ctx, "stripe")
+			require.False(t, api.IsCircuitOpen(err))
+		}
+		_, err := client.GetEventByName(ctx, "stripe")
+
+		var openErr *api.CircuitOpenError
+		require.True(t, errors.As(err, &openErr))
+		assert.Equal(t, "event", openErr.Endpoint)
+		assert.Equal(t, int32(3), eventCalls.Load(), "the open circuit does not reach the server")
+
+		states := client.CircuitStates()
+		require.Len(t, states, 1)
+		assert.Equal(t, api.CircuitOpen, states[0].State)
+		assert.NotNil(t, states[0].RetryAt)
+	})
+
+	t.Run("ScopedPerEndpoint", func(t *testing.T) {
+		healthy.Store(false)
+		client := newClient(api.CircuitBreakerPolicy{ConsecutiveFailures: 1, Window: 10, OpenTimeout: time.Minute})
+
+		_, _ = client.GetEventByName(ctx, "stripe")
+		_, err := client.ListAccessKeys(ctx, &api.ListParams{})
+
+		require.NoError(t, err)
+	})
+
+	t.Run("OpensOnFailureRate", func(t *testing.T) {
+		client := newClient(api.CircuitBreakerPolicy{FailureRate: 0.5, Window: 4, OpenTimeout: time.Minute})
+
+		for _, ok := range []bool{true, false, true, false} {
+			healthy.Store(ok)
+			_, err := client.GetEventByName(ctx, "stripe")
+			require.False(t, api.IsCircuitOpen(err))
+		}
+		healthy.Store(true)
+		_, err := client.GetEventByName(ctx, "stripe")
+
+		assert.True(t, api.IsCircuitOpen(err))
+	})
+
+	t.Run("HalfOpenProbeClosesCircuit", func(t *testing.T) {
+		healthy.Store(false)
+		client := newClient(api.CircuitBreakerPolicy{ConsecutiveFailures: 1, Window: 10, OpenTimeout: 50 * time.Millisecond})
+
+		_, _ = client.GetEventByName(ctx, "stripe")
+		_, err := client.GetEventByName(ctx, "stripe")
+		require.True(t, api.IsCircuitOpen(err))
+
+		// A failed probe opens the circuit again.
+		time.Sleep(60 * time.Millisecond)
+		_, err = client.GetEventByName(ctx, "stripe")
+		require.False(t, api.IsCircuitOpen(err))
+		_, err = client.GetEventByName(ctx, "stripe")
+		require.True(t, api.IsCircuitOpen(err))
+
+		healthy.Store(true)
+		time.Sleep(60 * time.Millisecond)
+		_, err = client.GetEventByName(ctx, "stripe")
+		require.NoError(t, err)
+
+		assert.Equal(t, api.CircuitClosed, client.CircuitStates()[0].State)
+	})
+
+	t.Run("OpenCircuitIsNotRetried", func(t *testing.T) {
+		eventCalls.Store(0)
+		healthy.Store(false)
+		breaker := api.NewCircuitBreaker(api.CircuitBreakerPolicy{ConsecutiveFailures: 2, Window: 10, OpenTimeout: time.Minute})
+		retries := api.DefaultRetryPolicy()
+		retries.WaitMin = time.Millisecond
+		retries.WaitMax = time.Millisecond
+		client := api.NewClient(server.URL, api.WithRetryPolicy(retries), api.WithCircuitBreaker(breaker))
+		client.SetAccessKey(testAccessKey)
+
+		_, err := client.GetEventByName(ctx, "stripe")
+
+		assert.True(t, api.IsCircuitOpen(err))
+		assert.Equal(t, int32(2), eventCalls.Load())
+	})
+}
@@ -0,0 +1,151 @@
+package integration
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/EnSync-engine/CLI/app/api"
+	"github.com/EnSync-engine/CLI/app/completion"
+	"github.com/EnSync-engine/CLI/app/domain"
+)
+
+func TestCompletion(t *testing.T) {
+	var lists atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lists.Add(1)
+		switch r.URL.Path {
+		case "/access-key":
+			writeJSON(w, domain.AccessKeyList{ResultsLength: 2, Results: []*domain.AccessKeyPermissions{
+				{ID: "key-2", Key: "ak_raw_2", Name: "billing"},
+				{ID: "key-1", Key: "ak_raw_1", Name: "checkout"},
+			}})
+		case "/event":
+			writeJSON(w, domain.EventList{ResultsLength: 2, Results: []*domain.Event{
+				{ID: "event-1", Name: "orders.shipped"},
+				{ID: "event-2", Name: "orders.created"},
+			}})
+		case "/workspace":
+			writeJSON(w, domain.WorkspaceList{ResultsLength: 1, Results: []*domain.Workspace{
+				{ID: "ws-1", Name: "gms", Children: []*domain.Workspace{{ID: "ws-2", Name: "urbanhero"}}},
+			}})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := api.NewClient(server.URL)
+	client.SetAccessKey(testAccessKey)
+	ctx := context.Background()
+
+	t.Run("FetchNames", func(t *testing.T) {
+		tests := []struct {
+			kind completion.Kind
+			want []string
+		}{
+			{kind: completion.Events, want: []string{"orders.created", "orders.shipped"}},
+			{kind: completion.AccessKeyIDs, want: []string{"key-1", "key-2"}},
+			{kind: completion.Workspaces, want: []string{"gms", "gms/urbanhero"}},
+		}
+
+		for _, tt := range tests {
+			t.Run(string(tt.kind), func(t *testing.T) {
+				names, err := completion.FetchNames(ctx, client, tt.kind)
+
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, names)
+			})
+		}
+	})
+
+	t.Run("CachesNames", func(t *testing.T) {
+		cache := completion.NewCache(t.TempDir(), time.Minute)
+		lists.Store(0)
+
+		for range 2 {
+			names, err := cache.Names(ctx, client, completion.Events, "events")
+			require.NoError(t, err)
+			assert.Equal(t, []string{"orders.created", "orders.shipped"}, names)
+		}
+
+		assert.Equal(t, int32(1), lists.Load(), "the second lookup is served from the cache")
+	})
+
+	t.Run("RefetchesExpiredNames", func(t *testing.T) {
+		cache := completion.NewCache(t.TempDir(), time.Nanosecond)
+		lists.Store(0)
+
+		for range 2 {
+			_, err := cache.Names(ctx, client, completion.Events, "events")
+			require.NoError(t, err)
+		}
+
+		assert.Equal(t, int32(2), lists.Load())
+	})
+
+	t.Run("PrunesExpiredEntries", func(t *testing.T) {
+		dir := t.TempDir()
+		stale := filepath.Join(dir, "stale.json")
+		require.NoError(t, os.WriteFile(stale, []byte(`{"names":["old"]}`), 0o600))
+		old := time.Now().Add(-time.Hour)
+		require.NoError(t, os.Chtimes(stale, old, old))
+
+		_, err := completion.NewCache(dir, time.Minute).Names(ctx, client, completion.Events, "events")
+
+		require.NoError(t, err)
+		_, err = os.Stat(stale)
+		assert.True(t, os.IsNotExist(err))
+		_, err = os.Stat(filepath.Join(dir, "events.json"))
+		assert.NoError(t, err)
+	})
+
+	t.Run("NeverStoresRawAccessKeys", func(t *testing.T) {
+		dir := t.TempDir()
+		key := completion.Key(server.URL, testAccessKey, string(completion.AccessKeyIDs))
+
+		names, err := completion.NewCache(dir, time.Minute).Names(ctx, client, completion.AccessKeyIDs, key)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"key-1", "key-2"}, names)
+		files, err := filepath.Glob(filepath.Join(dir, "*"))
+		require.NoError(t, err)
+		require.Len(t, files, 1)
+		assert.NotContains(t, filepath.Base(files[0]), testAccessKey, "entries are named by a hash")
+		data, err := os.ReadFile(files[0])
+		require.NoError(t, err)
+		assert.NotContains(t, string(data), "ak_raw_")
+	})
+}
+
+func TestCompletionCanAuthenticate(t *testing.T) {
+	oauth, err := api.NewOAuth2Authenticator(api.OAuth2Options{TokenURL: "https://auth.example/token", ClientID: "cli"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		auth api.Authenticator
+		want bool
+	}{
+		{name: "None", auth: nil, want: true},
+		{name: "StaticKey", auth: api.StaticKey("ak_1"), want: true},
+		{name: "KeyFile", auth: api.NewKeyFileAuthenticator(filepath.Join(t.TempDir(), "key")), want: true},
+		{name: "KeyCommand", auth: api.NewKeyCommandAuthenticator("pass show ensync"), want: false},
+		{name: "OAuth2", auth: oauth, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, completion.CanAuthenticate(tt.auth))
+		})
+	}
+}
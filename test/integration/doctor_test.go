@@ -0,0 +1,112 @@
+package integration
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/EnSync-engine/CLI/app/api"
+	"github.com/EnSync-engine/CLI/app/doctor"
+	"github.com/EnSync-engine/CLI/app/domain"
+)
+
+func TestDoctorChecks(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-EnSync-Version", "1.4.2")
+		w.Header().Set("X-RateLimit-Limit", "100")
+		w.Header().Set("X-RateLimit-Remaining", "5")
+		w.Header().Set("X-RateLimit-Reset", "30")
+		switch r.URL.Path {
+		case "/access-key/" + testAccessKey + "/permissions":
+			writeJSON(w, domain.AccessKeyPermissions{
+				Key:         testAccessKey,
+				Name:        "ci",
+				Permissions: &domain.Permissions{Send: []string{"gms/*"}},
+			})
+		case "/access-key/revoked/permissions":
+			w.WriteHeader(http.StatusUnauthorized)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := api.NewClient(server.URL, api.WithHTTPClient(server.Client()), api.WithRateLimit(100, 10))
+	ctx := context.Background()
+
+	probe, err := client.Probe(ctx)
+	require.NoError(t, err)
+
+	t.Run("Reachability", func(t *testing.T) {
+		result := doctor.CheckReachability(server.URL, probe, nil)
+
+		assert.Equal(t, doctor.StatusPass, result.Status)
+		assert.Contains(t, result.Detail, "answered 404")
+	})
+
+	t.Run("TLS", func(t *testing.T) {
+		result := doctor.CheckTLS(server.URL, probe, nil)
+
+		// httptest's certificate expires far in the future.
+		assert.Equal(t, doctor.StatusPass, result.Status)
+		assert.Contains(t, result.Detail, "TLS 1.3")
+	})
+
+	t.Run("UntrustedCertificate", func(t *testing.T) {
+		untrusted := api.NewClient(server.URL)
+		_, err := untrusted.Probe(ctx)
+		require.Error(t, err)
+
+		assert.Equal(t, doctor.StatusFail, doctor.CheckTLS(server.URL, nil, err).Status)
+		assert.Equal(t, doctor.StatusFail, doctor.CheckReachability(server.URL, nil, err).Status)
+	})
+
+	t.Run("ClockSkew", func(t *testing.T) {
+		assert.Equal(t, doctor.StatusPass, doctor.CheckClockSkew(probe, nil, time.Now()).Status)
+		assert.Equal(t, doctor.StatusWarn, doctor.CheckClockSkew(probe, nil, time.Now().Add(time.Minute)).Status)
+
+		result := doctor.CheckClockSkew(probe, nil, time.Now().Add(-10*time.Minute))
+		assert.Equal(t, doctor.StatusFail, result.Status)
+		assert.Contains(t, result.Detail, "behind")
+	})
+
+	t.Run("ServerVersion", func(t *testing.T) {
+		assert.Equal(t, doctor.StatusPass, doctor.CheckServerVersion(probe, nil, "1.0.0").Status)
+		assert.Equal(t, doctor.StatusFail, doctor.CheckServerVersion(&api.ProbeResult{ServerVersion: "v2.0.0"}, nil, "1.0.0").Status)
+		assert.Equal(t, doctor.StatusWarn, doctor.CheckServerVersion(&api.ProbeResult{}, nil, "1.0.0").Status)
+	})
+
+	t.Run("AccessKey", func(t *testing.T) {
+		result := doctor.CheckAccessKey(ctx, client, testAccessKey)
+
+		assert.Equal(t, doctor.StatusPass, result.Status)
+		assert.Contains(t, result.Detail, "ci (****")
+		assert.NotContains(t, result.Detail, testAccessKey)
+
+		assert.Equal(t, doctor.StatusFail, doctor.CheckAccessKey(ctx, client, "revoked").Status)
+		assert.Equal(t, doctor.StatusWarn, doctor.CheckAccessKey(ctx, client, "").Status)
+	})
+
+	t.Run("RateLimit", func(t *testing.T) {
+		quota, ok := client.RateLimitQuota()
+		require.True(t, ok)
+		assert.Equal(t, 100, quota.Limit)
+
+		result := doctor.CheckRateLimit(quota, ok)
+
+		assert.Equal(t, doctor.StatusWarn, result.Status)
+		assert.Contains(t, result.Detail, "5 of 100")
+	})
+
+	t.Run("Circuits", func(t *testing.T) {
+		open := []api.CircuitStatus{{Endpoint: "event", State: api.CircuitOpen}}
+
+		assert.Equal(t, doctor.StatusWarn, doctor.CheckCircuits(open, true).Status)
+		assert.Equal(t, doctor.StatusPass, doctor.CheckCircuits(nil, true).Status)
+	})
+}
@@ -0,0 +1,75 @@
+package integration
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/EnSync-engine/CLI/app/domain"
+)
+
+func TestNewEventPatch(t *testing.T) {
+	before := &domain.Event{
+		Name: "gms/a",
+		Payload: map[string]any{
+			"keep":    "same",
+			"removed": 1.0,
+			"nested":  map[string]any{"a": 1.0, "b": 2.0},
+		},
+	}
+
+	t.Run("OnlyChangedFields", func(t *testing.T) {
+		after := &domain.Event{
+			Name: "gms/a",
+			Payload: map[string]any{
+				"keep":   "same",
+				"added":  true,
+				"nested": map[string]any{"a": 1.0, "b": 3.0},
+			},
+		}
+
+		patch := domain.NewEventPatch(before, after)
+
+		assert.Nil(t, patch.Name)
+		assert.Equal(t, map[string]any{
+			"removed": nil,
+			"added":   true,
+			"nested":  map[string]any{"b": 3.0},
+		}, patch.Payload)
+	})
+
+	t.Run("Rename", func(t *testing.T) {
+		after := &domain.Event{Name: "gms/b", Payload: before.Payload}
+
+		patch := domain.NewEventPatch(before, after)
+
+		require.NotNil(t, patch.Name)
+		assert.Equal(t, "gms/b", *patch.Name)
+		assert.Nil(t, patch.Payload)
+	})
+
+	t.Run("NoChanges", func(t *testing.T) {
+		patch := domain.NewEventPatch(before, before)
+
+		assert.True(t, patch.IsEmpty())
+	})
+
+	t.Run("EncodesSetFieldsOnly", func(t *testing.T) {
+		name := "gms/b"
+		for _, tc := range []struct {
+			patch *domain.EventPatch
+			want  string
+		}{
+			{patch: &domain.EventPatch{Name: &name}, want: `{"name":"gms/b"}`},
+			{patch: &domain.EventPatch{Payload: map[string]any{}}, want: `{"payload":{}}`},
+			{patch: &domain.EventPatch{Payload: map[string]any{"old": nil}}, want: `{"payload":{"old":null}}`},
+		} {
+			data, err := json.Marshal(tc.patch)
+
+			require.NoError(t, err)
+			assert.JSONEq(t, tc.want, string(data))
+		}
+	})
+}
@@ -0,0 +1,20 @@
+package integration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/EnSync-engine/CLI/app/domain"
+)
+
+func TestPermissionsAddEventAlias(t *testing.T) {
+	perms := &domain.Permissions{Send: []string{"orders", "billing"}, Receive: []string{"billing"}}
+
+	expanded := perms.AddEventAlias("orders", "orders-v2")
+
+	assert.Equal(t, []string{"orders", "billing", "orders-v2"}, expanded.Send)
+	assert.Equal(t, []string{"billing"}, expanded.Receive, "lists without the name are left alone")
+	assert.Equal(t, []string{"orders", "billing"}, perms.Send, "the original is not modified")
+	assert.Equal(t, expanded.Send, expanded.AddEventAlias("orders", "orders-v2").Send, "adding twice is a no-op")
+}
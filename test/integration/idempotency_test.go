@@ -0,0 +1,140 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/EnSync-engine/CLI/app/api"
+	"github.com/EnSync-engine/CLI/app/domain"
+)
+
+func TestIdempotencyKeys(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		keys []string
+		fail bool
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		if fail {
+			fail = false
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	retryable := retryablehttp.NewClient()
+	retryable.RetryWaitMin = time.Millisecond
+	retryable.RetryWaitMax = time.Millisecond
+	retryable.Logger = nil
+	retryable.CheckRetry = api.IdempotentRetryPolicy
+
+	client := api.NewClient(server.URL, api.WithHTTPClient(retryable.StandardClient()))
+	client.SetAccessKey(testAccessKey)
+	ctx := context.Background()
+
+	reset := func(failFirst bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		keys = nil
+		fail = failFirst
+	}
+
+	t.Run("StableAcrossRetries", func(t *testing.T) {
+		reset(true)
+
+		require.NoError(t, client.CreateEvent(ctx, &domain.Event{Name: "stripe"}))
+
+		require.Len(t, keys, 2)
+		assert.NotEmpty(t, keys[0])
+		assert.Equal(t, keys[0], keys[1])
+	})
+
+	t.Run("GeneratedPerWrite", func(t *testing.T) {
+		reset(false)
+
+		require.NoError(t, client.CreateEvent(ctx, &domain.Event{Name: "a"}))
+		require.NoError(t, client.CreateEvent(ctx, &domain.Event{Name: "b"}))
+
+		require.Len(t, keys, 2)
+		assert.NotEqual(t, keys[0], keys[1])
+	})
+
+	t.Run("UserSupplied", func(t *testing.T) {
+		reset(false)
+		client.SetIdempotencyKey("import-42")
+		defer client.SetIdempotencyKey("")
+
+		require.NoError(t, client.CreateEvent(ctx, &domain.Event{Name: "a"}))
+		require.NoError(t, client.DeleteEvent(ctx, "event-1"))
+
+		assert.Equal(t, []string{"import-42", "import-42-2"}, keys)
+	})
+
+	t.Run("FromContext", func(t *testing.T) {
+		reset(false)
+		client.SetIdempotencyKey("ignored")
+		defer client.SetIdempotencyKey("")
+
+		require.NoError(t, client.CreateEvent(api.WithIdempotencyKey(ctx, "job-1-3"), &domain.Event{Name: "a"}))
+
+		assert.Equal(t, []string{"job-1-3"}, keys)
+	})
+
+	t.Run("NotSentOnReads", func(t *testing.T) {
+		reset(false)
+
+		_, _ = client.GetEventByName(ctx, "stripe")
+
+		assert.Equal(t, []string{""}, keys)
+	})
+}
+
+func TestIdempotentRetryPolicy(t *testing.T) {
+	ctx := context.Background()
+	unavailable := func(method, key string) *http.Response {
+		req := httptest.NewRequest(method, "/event", nil)
+		if key != "" {
+			req.Header.Set("Idempotency-Key", key)
+		}
+		return &http.Response{StatusCode: http.StatusServiceUnavailable, Request: req}
+	}
+
+	tests := []struct {
+		name  string
+		resp  *http.Response
+		err   error
+		retry bool
+	}{
+		{name: "SafeMethod", resp: unavailable(http.MethodGet, ""), retry: true},
+		{name: "UnsafeWithKey", resp: unavailable(http.MethodPost, "key"), retry: true},
+		{name: "UnsafeWithoutKey", resp: unavailable(http.MethodPost, ""), retry: false},
+		{name: "ConnectionErrorWithKey", err: errors.New("connection reset"), retry: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := ctx
+			if tt.err != nil {
+				ctx = api.WithIdempotencyKey(ctx, "key")
+			}
+
+			retry, _ := api.IdempotentRetryPolicy(ctx, tt.resp, tt.err)
+
+			assert.Equal(t, tt.retry, retry)
+		})
+	}
+}
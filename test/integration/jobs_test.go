@@ -0,0 +1,102 @@
+package integration
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/EnSync-engine/CLI/app/bulk"
+	"github.com/EnSync-engine/CLI/app/domain"
+	"github.com/EnSync-engine/CLI/app/jobs"
+)
+
+func TestJobStore(t *testing.T) {
+	dir := t.TempDir()
+	store := jobs.NewStore(dir)
+
+	records := []bulk.Record{
+		{Line: 1, Fields: map[string]any{"name": "gms/a"}},
+		{Line: 2, Fields: map[string]any{"name": "gms/b"}},
+		{Line: 4, Fields: map[string]any{"name": "gms/c"}},
+	}
+	job := &jobs.Job{Kind: "event-create", Workers: 2, Params: map[string]string{"type": "SERVICE"}}
+	require.NoError(t, store.Create(job, records))
+	require.NotEmpty(t, job.ID)
+
+	t.Run("Get", func(t *testing.T) {
+		got, err := store.Get(job.ID)
+
+		require.NoError(t, err)
+		assert.Equal(t, jobs.StatusRunning, got.Status)
+		assert.Equal(t, 3, got.Total)
+		assert.Equal(t, "SERVICE", got.Params["type"])
+
+		stored, err := store.Records(got)
+		require.NoError(t, err)
+		assert.Equal(t, records, stored)
+	})
+
+	t.Run("ResultsUseLatestJournalEntry", func(t *testing.T) {
+		journal, err := store.OpenJournal(job)
+		require.NoError(t, err)
+		require.NoError(t, journal.Append(bulk.Result{Line: 1, Status: bulk.StatusSucceeded}))
+		require.NoError(t, journal.Append(bulk.Result{Line: 2, Status: bulk.StatusFailed, Error: "boom"}))
+		require.NoError(t, journal.Append(bulk.Result{Line: 2, Status: bulk.StatusSucceeded}))
+		require.NoError(t, journal.Close())
+
+		// A process dying mid-write leaves a truncated line behind.
+		file, err := os.OpenFile(filepath.Join(dir, job.ID, "journal.ndjson"), os.O_APPEND|os.O_WRONLY, 0o600)
+		require.NoError(t, err)
+		_, err = file.WriteString(`{"line":4,"sta`)
+		require.NoError(t, err)
+		require.NoError(t, file.Close())
+
+		results, err := store.Results(job)
+
+		require.NoError(t, err)
+		require.Len(t, results, 3)
+		assert.Equal(t, bulk.StatusSucceeded, results[0].Status)
+		assert.Equal(t, bulk.StatusSucceeded, results[1].Status)
+		assert.Equal(t, bulk.Result{Line: 4, Status: bulk.StatusSkipped}, results[2])
+	})
+
+	t.Run("JournalOmitsOutputs", func(t *testing.T) {
+		journal, err := store.OpenJournal(job)
+		require.NoError(t, err)
+		created := domain.AccessKey{ID: "key-1", AccessKey: "ak_1", ServiceKeyPair: &domain.ServiceKeyPair{PrivateKey: "sk_1"}}
+		require.NoError(t, journal.Append(bulk.Result{Line: 1, Name: "ci", Status: bulk.StatusSucceeded, Output: created}))
+		require.NoError(t, journal.Close())
+
+		data, err := os.ReadFile(filepath.Join(dir, job.ID, "journal.ndjson"))
+
+		require.NoError(t, err)
+		assert.NotContains(t, string(data), "sk_1")
+		assert.NotContains(t, string(data), "ak_1")
+		assert.Contains(t, string(data), `"name":"ci"`)
+	})
+
+	t.Run("Cancel", func(t *testing.T) {
+		cancelled, err := store.Cancel(job.ID)
+
+		require.NoError(t, err)
+		assert.Equal(t, jobs.StatusCancelled, cancelled.Status)
+		assert.False(t, cancelled.Resumable())
+	})
+
+	t.Run("List", func(t *testing.T) {
+		list, err := store.List()
+
+		require.NoError(t, err)
+		require.Len(t, list, 1)
+		assert.Equal(t, job.ID, list[0].ID)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		_, err := store.Get("missing")
+
+		assert.ErrorIs(t, err, jobs.ErrNotFound)
+	})
+}
@@ -0,0 +1,82 @@
+package integration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/EnSync-engine/CLI/app/api"
+)
+
+// pagesOf serves items in pages of params.Limit, reporting total as the
+// result count, and records the params of every request.
+func pagesOf(items []int, total int, requests *[]api.ListParams) api.PageFetcher[int] {
+	return func(_ context.Context, params *api.ListParams) ([]int, int, error) {
+		*requests = append(*requests, *params)
+		start := min(params.PageIndex*params.Limit, len(items))
+		end := min(start+params.Limit, len(items))
+		return items[start:end], total, nil
+	}
+}
+
+func TestCollectPages(t *testing.T) {
+	items := make([]int, 250)
+	for i := range items {
+		items[i] = i
+	}
+
+	tests := []struct {
+		name     string
+		items    []int
+		total    int
+		want     int
+		requests int
+	}{
+		{name: "Empty", items: nil, total: 0, want: 0, requests: 1},
+		{name: "ShortLastPage", items: items, total: 250, want: 250, requests: 3},
+		{name: "ExactPages", items: items[:200], total: 200, want: 200, requests: 2},
+		{name: "TotalUnderreported", items: items[:200], total: 100, want: 100, requests: 1},
+		{name: "TotalOverreported", items: items[:200], total: 1000, want: 200, requests: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests []api.ListParams
+
+			all, err := api.CollectPages(context.Background(), nil, pagesOf(tt.items, tt.total, &requests))
+
+			require.NoError(t, err)
+			assert.Len(t, all, tt.want)
+			assert.Len(t, requests, tt.requests)
+		})
+	}
+
+	t.Run("KeepsFiltersAndOrder", func(t *testing.T) {
+		var requests []api.ListParams
+		params := &api.ListParams{PageIndex: 3, Limit: 5, Order: "ASC", OrderBy: "name", Filter: map[string]string{"name": "ci"}}
+
+		_, err := api.CollectPages(context.Background(), params, pagesOf(items, 250, &requests))
+
+		require.NoError(t, err)
+		require.Len(t, requests, 3)
+		assert.Equal(t, 0, requests[0].PageIndex, "walking starts at the first page")
+		assert.Equal(t, 100, requests[0].Limit)
+		assert.Equal(t, "ASC", requests[2].Order)
+		assert.Equal(t, map[string]string{"name": "ci"}, requests[2].Filter)
+		assert.Equal(t, 3, params.PageIndex, "the caller's params are left alone")
+	})
+}
+
+func TestWalkPagesStops(t *testing.T) {
+	var requests []api.ListParams
+	items := make([]int, 300)
+
+	err := api.WalkPages(context.Background(), nil, pagesOf(items, 300, &requests), func([]int) bool {
+		return false
+	})
+
+	require.NoError(t, err)
+	assert.Len(t, requests, 1)
+}
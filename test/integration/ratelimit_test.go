@@ -0,0 +1,153 @@
+package integration
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/EnSync-engine/CLI/app/api"
+	"github.com/EnSync-engine/CLI/app/domain"
+)
+
+func TestRateLimiterObserve(t *testing.T) {
+	response := func(status int, remaining, reset string) *http.Response {
+		header := http.Header{}
+		if remaining != "" {
+			header.Set("X-RateLimit-Remaining", remaining)
+		}
+		if reset != "" {
+			header.Set("X-RateLimit-Reset", reset)
+		}
+		return &http.Response{StatusCode: status, Header: header}
+	}
+
+	t.Run("SpreadsRemainingQuotaOverWindow", func(t *testing.T) {
+		limiter := api.NewRateLimiter(10, 20)
+
+		limiter.Observe(response(http.StatusOK, "5", "10"))
+
+		assert.InDelta(t, 0.5, limiter.Limit(), 0.01)
+	})
+
+	t.Run("NeverExceedsStaticRate", func(t *testing.T) {
+		limiter := api.NewRateLimiter(10, 20)
+
+		limiter.Observe(response(http.StatusOK, "5000", "10"))
+
+		assert.InDelta(t, 10, limiter.Limit(), 0.01)
+	})
+
+	t.Run("AcceptsUnixTimestampReset", func(t *testing.T) {
+		limiter := api.NewRateLimiter(10, 20)
+		reset := time.Now().Add(20 * time.Second).Unix()
+
+		limiter.Observe(response(http.StatusOK, "10", strconv.FormatInt(reset, 10)))
+
+		assert.InDelta(t, 0.5, limiter.Limit(), 0.05)
+	})
+
+	t.Run("IgnoresResponsesWithoutHeaders", func(t *testing.T) {
+		limiter := api.NewRateLimiter(10, 20)
+
+		limiter.Observe(response(http.StatusOK, "", ""))
+
+		assert.InDelta(t, 10, limiter.Limit(), 0.01)
+	})
+
+	t.Run("KeepsBurstsWithinServerLimit", func(t *testing.T) {
+		limiter := api.NewRateLimiter(10, 20)
+		resp := response(http.StatusOK, "50", "10")
+		resp.Header.Set("X-RateLimit-Limit", "5")
+
+		limiter.Observe(resp)
+
+		assert.Equal(t, 5, limiter.Burst())
+		assert.InDelta(t, 0.5, limiter.Limit(), 0.01, "remaining is capped at the limit")
+	})
+
+	t.Run("RestoresRateWhenWindowResets", func(t *testing.T) {
+		limiter := api.NewRateLimiter(10, 20)
+		resp := response(http.StatusOK, "1", "0.2")
+		resp.Header.Set("X-RateLimit-Limit", "2")
+		limiter.Observe(resp)
+		require.Less(t, limiter.Limit(), 10.0)
+
+		time.Sleep(250 * time.Millisecond)
+
+		assert.InDelta(t, 10, limiter.Limit(), 0.01, "restored without another response")
+		assert.Equal(t, 20, limiter.Burst())
+	})
+
+	t.Run("ExhaustedQuotaPausesRequests", func(t *testing.T) {
+		limiter := api.NewRateLimiter(10, 20)
+		limiter.Observe(response(http.StatusOK, "0", "0.2"))
+
+		start := time.Now()
+		require.NoError(t, limiter.Wait(context.Background()))
+
+		assert.GreaterOrEqual(t, time.Since(start), 150*time.Millisecond)
+	})
+}
+
+func TestRateLimitThrottlesWorkers(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Header().Set("X-RateLimit-Reset", "0.3")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		writeJSON(w, domain.Event{ID: "event-1", Name: "stripe"})
+	}))
+	defer server.Close()
+
+	var (
+		mu     sync.Mutex
+		waits  []time.Duration
+		policy = api.DefaultRetryPolicy()
+	)
+	policy.MaxRetries = 0
+
+	client := api.NewClient(server.URL,
+		api.WithRetryPolicy(policy),
+		api.WithRateLimit(100, 10),
+		api.WithThrottleHandler(func(wait time.Duration) {
+			mu.Lock()
+			defer mu.Unlock()
+			waits = append(waits, wait)
+		}),
+	)
+	client.SetAccessKey(testAccessKey)
+	ctx := context.Background()
+
+	// Once one request is throttled, every worker waits for the window
+	// to reset.
+	_, err := client.GetEventByName(ctx, "stripe")
+	require.Error(t, err)
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for range 3 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := client.GetEventByName(ctx, "stripe")
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(4), calls.Load())
+	assert.GreaterOrEqual(t, time.Since(start), 250*time.Millisecond)
+	require.Len(t, waits, 1, "a pause is reported once")
+	assert.Greater(t, waits[0], 250*time.Millisecond)
+}
@@ -0,0 +1,126 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/EnSync-engine/CLI/app/api"
+	"github.com/EnSync-engine/CLI/app/domain"
+)
+
+func TestRequestIDs(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		ids      []string
+		fail     bool
+		serverID string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		ids = append(ids, r.Header.Get("X-Request-ID"))
+		if serverID != "" {
+			w.Header().Set("X-Request-ID", serverID)
+		}
+		if r.URL.Path == "/event/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if fail {
+			fail = false
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		writeJSON(w, domain.Event{ID: "event-1", Name: "stripe"})
+	}))
+	defer server.Close()
+
+	policy := api.DefaultRetryPolicy()
+	policy.WaitMin = time.Millisecond
+	policy.WaitMax = time.Millisecond
+	client := api.NewClient(server.URL, api.WithRetryPolicy(policy))
+	client.SetAccessKey(testAccessKey)
+	ctx := context.Background()
+
+	reset := func(failFirst bool, echo string) {
+		mu.Lock()
+		defer mu.Unlock()
+		ids = nil
+		fail = failFirst
+		serverID = echo
+	}
+
+	t.Run("StableAcrossRetries", func(t *testing.T) {
+		reset(true, "")
+
+		_, err := client.GetEventByName(ctx, "stripe")
+
+		require.NoError(t, err)
+		require.Len(t, ids, 2)
+		assert.NotEmpty(t, ids[0])
+		assert.Equal(t, ids[0], ids[1])
+	})
+
+	t.Run("GeneratedPerOperation", func(t *testing.T) {
+		reset(false, "")
+
+		_, _ = client.GetEventByName(ctx, "stripe")
+		_, _ = client.GetEventByName(ctx, "stripe")
+
+		require.Len(t, ids, 2)
+		assert.NotEqual(t, ids[0], ids[1])
+	})
+
+	t.Run("UserSupplied", func(t *testing.T) {
+		reset(false, "")
+		client.SetRequestID("support-123")
+		defer client.SetRequestID("")
+
+		_, _ = client.GetEventByName(ctx, "stripe")
+		_, _ = client.GetEventByName(api.WithRequestID(ctx, "from-context"), "stripe")
+
+		assert.Equal(t, []string{"support-123", "from-context"}, ids)
+	})
+
+	t.Run("IncludedInAPIError", func(t *testing.T) {
+		reset(false, "")
+
+		_, err := client.GetEventByName(ctx, "missing")
+
+		var apiErr *api.APIError
+		require.True(t, errors.As(err, &apiErr))
+		assert.Equal(t, ids[0], apiErr.RequestID)
+		assert.Equal(t, ids[0], api.RequestID(err))
+		assert.Contains(t, err.Error(), ids[0])
+	})
+
+	t.Run("ServerAssignedIDWins", func(t *testing.T) {
+		reset(false, "srv-42")
+
+		_, err := client.GetEventByName(ctx, "missing")
+
+		assert.Equal(t, "srv-42", api.RequestID(err))
+	})
+
+	t.Run("IncludedInRequestError", func(t *testing.T) {
+		noRetries := api.DefaultRetryPolicy()
+		noRetries.MaxRetries = 0
+		unreachable := api.NewClient("http://127.0.0.1:1", api.WithRetryPolicy(noRetries))
+		unreachable.SetRequestID("support-456")
+
+		_, err := unreachable.GetEventByName(ctx, "stripe")
+
+		var reqErr *api.RequestError
+		require.True(t, errors.As(err, &reqErr))
+		assert.Equal(t, "support-456", reqErr.RequestID)
+		assert.Contains(t, err.Error(), "support-456")
+	})
+}
@@ -0,0 +1,113 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/EnSync-engine/CLI/app/api"
+	"github.com/EnSync-engine/CLI/app/domain"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	var (
+		calls    atomic.Int32
+		failures atomic.Int32
+		status   atomic.Int32
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if failures.Add(-1) >= 0 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(int(status.Load()))
+			return
+		}
+		writeJSON(w, domain.Event{ID: "event-1", Name: "stripe"})
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	newClient := func(policy api.RetryPolicy) *api.Client {
+		policy.WaitMin = time.Millisecond
+		policy.WaitMax = 2 * time.Millisecond
+		client := api.NewClient(server.URL, api.WithRetryPolicy(policy))
+		client.SetAccessKey(testAccessKey)
+		return client
+	}
+	reset := func(failFirst int, code int) {
+		calls.Store(0)
+		failures.Store(int32(failFirst))
+		status.Store(int32(code))
+	}
+
+	t.Run("RetriesUntilSuccess", func(t *testing.T) {
+		reset(2, http.StatusServiceUnavailable)
+		client := newClient(api.DefaultRetryPolicy())
+
+		event, err := client.GetEventByName(ctx, "stripe")
+
+		require.NoError(t, err)
+		assert.Equal(t, "event-1", event.ID)
+		assert.Equal(t, int32(3), calls.Load())
+	})
+
+	t.Run("ExhaustedRetriesReturnAPIError", func(t *testing.T) {
+		reset(5, http.StatusServiceUnavailable)
+		policy := api.DefaultRetryPolicy()
+		policy.MaxRetries = 1
+		client := newClient(policy)
+
+		_, err := client.GetEventByName(ctx, "stripe")
+
+		var apiErr *api.APIError
+		require.True(t, errors.As(err, &apiErr))
+		assert.Equal(t, http.StatusServiceUnavailable, apiErr.StatusCode)
+		assert.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("OnlyConfiguredStatusCodes", func(t *testing.T) {
+		reset(1, http.StatusInternalServerError)
+		policy := api.DefaultRetryPolicy()
+		policy.StatusCodes = []int{http.StatusTeapot}
+		client := newClient(policy)
+
+		_, err := client.GetEventByName(ctx, "stripe")
+
+		require.Error(t, err)
+		assert.Equal(t, int32(1), calls.Load())
+
+		reset(1, http.StatusTeapot)
+		_, err = client.GetEventByName(ctx, "stripe")
+
+		require.NoError(t, err)
+		assert.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("NoRetries", func(t *testing.T) {
+		reset(1, http.StatusServiceUnavailable)
+		policy := api.DefaultRetryPolicy()
+		policy.MaxRetries = 0
+		client := newClient(policy)
+
+		_, err := client.GetEventByName(ctx, "stripe")
+
+		require.Error(t, err)
+		assert.Equal(t, int32(1), calls.Load())
+	})
+}
+
+func TestParseBackoff(t *testing.T) {
+	backoff, err := api.ParseBackoff("exponential-jitter")
+	require.NoError(t, err)
+	assert.Equal(t, api.BackoffExponentialJitter, backoff)
+
+	_, err = api.ParseBackoff("linear")
+	assert.Error(t, err)
+}
@@ -0,0 +1,181 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/EnSync-engine/CLI/app/bundle"
+	"github.com/EnSync-engine/CLI/app/domain"
+	"github.com/EnSync-engine/CLI/app/secrets"
+)
+
+func testSecrets() []secrets.Secret {
+	issued := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	return []secrets.Secret{
+		{ID: "key-1", Name: "billing api", AccessKey: "ak_1", ServiceKeyPair: &domain.ServiceKeyPair{PublicKey: "pk_1", PrivateKey: "sk_1"}, IssuedAt: issued},
+		{ID: "key-2", Name: "billing api", AccessKey: "ak_2", ServiceKeyPair: &domain.ServiceKeyPair{PublicKey: "pk_2", PrivateKey: "sk_2"}, IssuedAt: issued},
+	}
+}
+
+func putAll(t *testing.T, sink secrets.Sink) {
+	t.Helper()
+	for _, secret := range testSecrets() {
+		require.NoError(t, sink.Put(context.Background(), secret))
+	}
+}
+
+func TestFileSinks(t *testing.T) {
+	t.Run("File", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "keys.json")
+		sink, err := secrets.NewFileSink(path)
+		require.NoError(t, err)
+
+		putAll(t, sink)
+
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Zero(t, info.Mode().Perm()&0o077, "the file is only readable by the user")
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		var stored []secrets.Secret
+		require.NoError(t, json.Unmarshal(data, &stored))
+		assert.Equal(t, testSecrets(), stored, "every secret put is kept")
+	})
+
+	t.Run("RefusesExistingFile", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "keys.json")
+		require.NoError(t, os.WriteFile(path, []byte("earlier keys"), 0o600))
+
+		_, err := secrets.NewFileSink(path)
+
+		assert.ErrorContains(t, err, "already exists")
+	})
+
+	t.Run("Bundle", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "keys.bundle")
+		sink, err := secrets.NewBundleSink(path, "correct horse battery")
+		require.NoError(t, err)
+
+		putAll(t, sink)
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.NotContains(t, string(data), "sk_1")
+		plaintext, err := bundle.ReadFile(path, "correct horse battery")
+		require.NoError(t, err)
+		var stored []secrets.Secret
+		require.NoError(t, json.Unmarshal(plaintext, &stored))
+		assert.Equal(t, testSecrets(), stored)
+
+		_, err = secrets.NewBundleSink(filepath.Join(t.TempDir(), "short.bundle"), "short")
+		assert.ErrorIs(t, err, bundle.ErrShortPassphrase)
+	})
+
+	t.Run("Dotenv", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), ".env")
+		sink, err := secrets.NewDotenvSink(path)
+		require.NoError(t, err)
+
+		putAll(t, sink)
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `ENSYNC_BILLING_API_ACCESS_KEY="ak_1"`)
+		assert.Contains(t, string(data), `ENSYNC_BILLING_API_PRIVATE_KEY="sk_1"`)
+		assert.Contains(t, string(data), `ENSYNC_BILLING_API_KEY_2_PRIVATE_KEY="sk_2"`, "keys sharing a name get their ID appended")
+	})
+
+	t.Run("Kubernetes", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "secrets.yaml")
+		sink, err := secrets.NewKubernetesSink(path)
+		require.NoError(t, err)
+
+		putAll(t, sink)
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		var manifests []map[string]any
+		for {
+			var manifest map[string]any
+			err := decoder.Decode(&manifest)
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			require.NoError(t, err)
+			manifests = append(manifests, manifest)
+		}
+		require.Len(t, manifests, 2)
+		assert.Equal(t, "Secret", manifests[0]["kind"])
+		assert.Equal(t, "ensync-billing-api", manifests[0]["metadata"].(map[string]any)["name"])
+		assert.Equal(t, "ensync-billing-api-key-2", manifests[1]["metadata"].(map[string]any)["name"])
+		assert.Equal(t, "sk_2", manifests[1]["stringData"].(map[string]any)["ENSYNC_PRIVATE_KEY"])
+	})
+}
+
+func TestVaultSink(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		written = make(map[string]map[string]string)
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root" {
+			w.WriteHeader(http.StatusForbidden)
+			writeJSON(w, map[string][]string{"errors": {"permission denied"}})
+			return
+		}
+		var body struct {
+			Data map[string]string `json:"data"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		mu.Lock()
+		written[r.Method+" "+r.URL.Path] = body.Data
+		mu.Unlock()
+		writeJSON(w, map[string]any{"data": map[string]int{"version": 1}})
+	}))
+	defer server.Close()
+
+	t.Run("WritesKV2", func(t *testing.T) {
+		sink, err := secrets.NewVaultSink(secrets.VaultOptions{Address: server.URL, Token: "root", Mount: "secret", Path: "/ensync/prod/"})
+		require.NoError(t, err)
+
+		putAll(t, sink)
+
+		assert.Equal(t, map[string]string{"access_key": "ak_1", "public_key": "pk_1", "private_key": "sk_1"},
+			written["POST /v1/secret/data/ensync/prod/billing-api"])
+		assert.Equal(t, "sk_2", written["POST /v1/secret/data/ensync/prod/billing-api-key-2"]["private_key"])
+		assert.True(t, strings.HasPrefix(sink.String(), "Vault secret/ensync/prod"))
+	})
+
+	t.Run("SurfacesErrors", func(t *testing.T) {
+		sink, err := secrets.NewVaultSink(secrets.VaultOptions{Address: server.URL, Token: "wrong", Mount: "secret"})
+		require.NoError(t, err)
+
+		err = sink.Put(context.Background(), testSecrets()[0])
+
+		assert.ErrorContains(t, err, "status 403: permission denied")
+	})
+
+	t.Run("InvalidOptions", func(t *testing.T) {
+		_, err := secrets.NewVaultSink(secrets.VaultOptions{Address: "vault.example:8200", Token: "root", Mount: "secret"})
+		assert.Error(t, err)
+
+		_, err = secrets.NewVaultSink(secrets.VaultOptions{Address: server.URL, Mount: "secret"})
+		assert.Error(t, err)
+	})
+}
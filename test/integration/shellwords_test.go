@@ -0,0 +1,140 @@
+package integration
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/EnSync-engine/CLI/pkg/shellwords"
+)
+
+func TestSplitPipeline(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want []string
+	}{
+		{name: "Single", line: "event list", want: []string{"event list"}},
+		{name: "Stages", line: "event list | !jq .results |  !wc -l ", want: []string{"event list", "!jq .results", "!wc -l"}},
+		{name: "QuotedPipes", line: `event list | !jq '.results | length' | !grep "a|b"`, want: []string{"event list", "!jq '.results | length'", `!grep "a|b"`}},
+		{name: "EscapedPipe", line: `!echo a \| b`, want: []string{`!echo a \| b`}},
+		{name: "EscapedQuote", line: `!echo "a \" | b" | !cat`, want: []string{`!echo "a \" | b"`, "!cat"}},
+		{name: "MissingCommands", line: "| event list || !cat |", want: []string{"", "event list", "", "!cat", ""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, shellwords.SplitPipeline(tt.line))
+		})
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	vars := map[string]string{
+		"name": "gms/urbanhero",
+		"last": `{"results":[{"name":"orders created"}]}`,
+	}
+	lookup := func(ref string) (string, error) {
+		if ref == "last.results.0.name" {
+			return "orders created", nil
+		}
+		value, ok := vars[ref]
+		if !ok {
+			return "", fmt.Errorf("undefined variable $%s", ref)
+		}
+		return value, nil
+	}
+
+	tests := []struct {
+		name string
+		line string
+		want []string
+	}{
+		{name: "Spaces", line: "  event   get\tstripe  ", want: []string{"event", "get", "stripe"}},
+		{name: "Empty", line: "   ", want: nil},
+		{name: "DoubleQuotes", line: `event create --payload "{\"a\": 1}"`, want: []string{"event", "create", "--payload", `{"a": 1}`}},
+		{name: "SingleQuotes", line: `event create --payload '{"a": "b\c"}'`, want: []string{"event", "create", "--payload", `{"a": "b\c"}`}},
+		{name: "EmptyQuotes", line: `event update --name ""`, want: []string{"event", "update", "--name", ""}},
+		{name: "AdjacentQuotes", line: `a'b c'"d e"f`, want: []string{"ab cd ef"}},
+		{name: "EscapedSpace", line: `event get orders\ created`, want: []string{"event", "get", "orders created"}},
+		{name: "TrailingBackslash", line: `event get a\`, want: []string{"event", "get", `a\`}},
+		{name: "Variable", line: "workspace get $name", want: []string{"workspace", "get", "gms/urbanhero"}},
+		{name: "BracedVariable", line: "x${name}y", want: []string{"xgms/urbanheroy"}},
+		{name: "VariableInDoubleQuotes", line: `"at $name."`, want: []string{"at gms/urbanhero."}},
+		{name: "VariableInSingleQuotes", line: `'$name'`, want: []string{"$name"}},
+		{name: "EscapedDollar", line: `\$name`, want: []string{"$name"}},
+		{name: "LoneDollar", line: "costs $ 5", want: []string{"costs", "$", "5"}},
+		{name: "JSONPath", line: "event get $last.results.0.name", want: []string{"event", "get", "orders created"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := shellwords.Tokenize(tt.line, lookup)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("UnterminatedQuote", func(t *testing.T) {
+		_, err := shellwords.Tokenize(`event get "stripe`, lookup)
+		assert.EqualError(t, err, `unterminated " quote`)
+
+		_, err = shellwords.Tokenize(`event get 'stripe`, lookup)
+		assert.EqualError(t, err, `unterminated ' quote`)
+	})
+
+	t.Run("UndefinedVariable", func(t *testing.T) {
+		_, err := shellwords.Tokenize("event get $missing", lookup)
+
+		assert.EqualError(t, err, "undefined variable $missing")
+	})
+}
+
+func TestLookupPath(t *testing.T) {
+	const document = ` {"results":[{"name":"orders","payload":{"b":2,"a":1}}],"resultsLength":1}
+`
+
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{name: "Document", path: "", want: `{"results":[{"name":"orders","payload":{"b":2,"a":1}}],"resultsLength":1}`},
+		{name: "String", path: "results.0.name", want: "orders"},
+		{name: "Number", path: "resultsLength", want: "1"},
+		{name: "Object", path: "results.0.payload", want: `{"a":1,"b":2}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := shellwords.LookupPath(document, tt.path)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	errorTests := []struct {
+		name     string
+		document string
+		path     string
+		err      string
+	}{
+		{name: "MissingField", document: document, path: "results.0.id", err: `no field "id"`},
+		{name: "IndexOutOfRange", document: document, path: "results.1", err: `invalid index "1"`},
+		{name: "NonNumericIndex", document: document, path: "results.first", err: `invalid index "first"`},
+		{name: "Scalar", document: document, path: "resultsLength.value", err: `cannot index "value" into a scalar`},
+		{name: "NotJSON", document: "Event created", path: "id", err: `value is not JSON, cannot resolve "id"`},
+	}
+
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := shellwords.LookupPath(tt.document, tt.path)
+
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
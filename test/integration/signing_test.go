@@ -0,0 +1,233 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/EnSync-engine/CLI/app/api"
+	"github.com/EnSync-engine/CLI/app/domain"
+	"github.com/EnSync-engine/CLI/pkg/signing"
+)
+
+var testKeyPair = domain.ServiceKeyPair{PublicKey: "pk_test", PrivateKey: "sk_test_secret"}
+
+func testKeys(publicKey string) (string, bool) {
+	if publicKey == testKeyPair.PublicKey {
+		return testKeyPair.PrivateKey, true
+	}
+	return "", false
+}
+
+func TestRequestSigning(t *testing.T) {
+	verifier := signing.NewVerifier(testKeys)
+	var (
+		mu       sync.Mutex
+		captured *http.Request
+		body     []byte
+	)
+	server := httptest.NewServer(verifier.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		captured = r.Clone(context.Background())
+		body, _ = io.ReadAll(r.Body)
+		mu.Unlock()
+		writeJSON(w, domain.EventList{})
+	})))
+	defer server.Close()
+
+	client := api.NewClient(server.URL, api.WithSigning(&testKeyPair))
+	client.SetAccessKey(testAccessKey)
+	ctx := context.Background()
+
+	t.Run("SignsRequests", func(t *testing.T) {
+		_, err := client.ListEvents(ctx, api.DefaultListParams())
+		require.NoError(t, err)
+
+		err = client.CreateEvent(ctx, &domain.Event{Name: "gms/stripe", Payload: map[string]any{"amount": "int"}})
+		require.NoError(t, err)
+
+		assert.Equal(t, testKeyPair.PublicKey, captured.Header.Get(signing.HeaderKey))
+		assert.Contains(t, string(body), "gms/stripe", "the body reaches the handler after verification")
+		assert.NotContains(t, captured.Header.Get(signing.HeaderSignature), testKeyPair.PrivateKey)
+	})
+
+	t.Run("OmitsAccessKey", func(t *testing.T) {
+		client := api.NewClient(server.URL, api.WithSigning(&testKeyPair), api.WithOmitAccessKey(true))
+		client.SetAccessKey(testAccessKey)
+		client.SetWorkspace("gms/urbanhero")
+
+		_, err := client.ListEvents(ctx, api.DefaultListParams())
+
+		require.NoError(t, err)
+		assert.Empty(t, captured.Header.Get("X-ACCESS-KEY"))
+		assert.Equal(t, "gms/urbanhero", captured.Header.Get("X-ENSYNC-WORKSPACE"))
+	})
+
+	t.Run("RejectsReplay", func(t *testing.T) {
+		replay := captured.Clone(ctx)
+		replay.RequestURI = ""
+		replay.URL.Scheme, replay.URL.Host = "http", server.Listener.Addr().String()
+		replay.Body = io.NopCloser(bytes.NewReader(body))
+
+		resp, err := http.DefaultClient.Do(replay)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("RejectsUnsigned", func(t *testing.T) {
+		unsigned := api.NewClient(server.URL)
+		unsigned.SetAccessKey(testAccessKey)
+
+		_, err := unsigned.ListEvents(ctx, api.DefaultListParams())
+
+		var apiErr *api.APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, http.StatusUnauthorized, apiErr.StatusCode)
+	})
+
+	t.Run("SignsEachRetry", func(t *testing.T) {
+		var attempts atomic.Int32
+		flaky := httptest.NewServer(verifier.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if attempts.Add(1) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			writeJSON(w, domain.EventList{})
+		})))
+		defer flaky.Close()
+		policy := api.DefaultRetryPolicy()
+		policy.WaitMin, policy.WaitMax = time.Millisecond, time.Millisecond
+		client := api.NewClient(flaky.URL, api.WithSigning(&testKeyPair), api.WithRetryPolicy(policy))
+
+		_, err := client.ListEvents(ctx, api.DefaultListParams())
+
+		require.NoError(t, err)
+		assert.Equal(t, int32(2), attempts.Load(), "the retry carries a fresh nonce, so it is not taken for a replay")
+	})
+}
+
+func TestSignatureVerification(t *testing.T) {
+	now := time.Now()
+	newRequest := func(t *testing.T, keyPair domain.ServiceKeyPair, signedAt time.Time) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/ensync/event?dryRun=true", bytes.NewReader([]byte(`{"name":"gms/stripe"}`)))
+		req.Header.Set("X-ENSYNC-WORKSPACE", "gms/urbanhero")
+		req.Header.Set("Idempotency-Key", "key-1")
+		require.NoError(t, signing.Sign(req, keyPair.PublicKey, keyPair.PrivateKey, signedAt))
+		return req
+	}
+
+	t.Run("Valid", func(t *testing.T) {
+		publicKey, err := signing.NewVerifier(testKeys).Verify(newRequest(t, testKeyPair, now))
+
+		require.NoError(t, err)
+		assert.Equal(t, testKeyPair.PublicKey, publicKey)
+	})
+
+	for name, tc := range map[string]struct {
+		tamper func(*http.Request)
+		keys   domain.ServiceKeyPair
+		at     time.Time
+		want   error
+	}{
+		"TamperedBody": {
+			tamper: func(r *http.Request) { r.Body = io.NopCloser(bytes.NewReader([]byte(`{"name":"gms/other"}`))) },
+			want:   signing.ErrContentMismatch,
+		},
+		"TamperedQuery": {
+			tamper: func(r *http.Request) { r.URL.RawQuery = "dryRun=false" },
+			want:   signing.ErrInvalidSignature,
+		},
+		"TamperedMethod": {
+			tamper: func(r *http.Request) { r.Method = http.MethodDelete },
+			want:   signing.ErrInvalidSignature,
+		},
+		"TamperedWorkspace": {
+			tamper: func(r *http.Request) { r.Header.Set("X-ENSYNC-WORKSPACE", "gms/other") },
+			want:   signing.ErrInvalidSignature,
+		},
+		"RemovedWorkspace": {
+			tamper: func(r *http.Request) { r.Header.Del("X-ENSYNC-WORKSPACE") },
+			want:   signing.ErrInvalidSignature,
+		},
+		"AddedAccessKey": {
+			tamper: func(r *http.Request) { r.Header.Set("X-ACCESS-KEY", "someone-elses-key") },
+			want:   signing.ErrInvalidSignature,
+		},
+		"TamperedIdempotencyKey": {
+			tamper: func(r *http.Request) { r.Header.Set("Idempotency-Key", "key-2") },
+			want:   signing.ErrInvalidSignature,
+		},
+		"TamperedHost": {
+			tamper: func(r *http.Request) { r.Host = "other.example.com" },
+			want:   signing.ErrInvalidSignature,
+		},
+		"TamperedContentType": {
+			tamper: func(r *http.Request) { r.Header.Set("Content-Type", "application/merge-patch+json") },
+			want:   signing.ErrInvalidSignature,
+		},
+		"WorkspaceLeftUnsigned": {
+			tamper: func(r *http.Request) {
+				r.Header.Set(signing.HeaderSignedHeaders, "content-type;host;idempotency-key;x-access-key")
+			},
+			want: signing.ErrUnsignedHeader,
+		},
+		"UnsignedHeadersUnchecked": {
+			tamper: func(r *http.Request) { r.Header.Set("X-Request-ID", "changed") },
+		},
+		"WrongPrivateKey": {
+			keys: domain.ServiceKeyPair{PublicKey: testKeyPair.PublicKey, PrivateKey: "guessed"},
+			want: signing.ErrInvalidSignature,
+		},
+		"UnknownKey": {
+			keys: domain.ServiceKeyPair{PublicKey: "pk_other", PrivateKey: "sk_other"},
+			want: signing.ErrUnknownKey,
+		},
+		"Stale": {
+			at:   now.Add(-10 * time.Minute),
+			want: signing.ErrStale,
+		},
+		"Unsigned": {
+			tamper: func(r *http.Request) { r.Header.Del(signing.HeaderSignature) },
+			want:   signing.ErrUnsigned,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			keys, at := tc.keys, tc.at
+			if keys == (domain.ServiceKeyPair{}) {
+				keys = testKeyPair
+			}
+			if at.IsZero() {
+				at = now
+			}
+			req := newRequest(t, keys, at)
+			if tc.tamper != nil {
+				tc.tamper(req)
+			}
+
+			_, err := signing.NewVerifier(testKeys).Verify(req)
+
+			assert.ErrorIs(t, err, tc.want)
+		})
+	}
+
+	t.Run("MaxSkew", func(t *testing.T) {
+		verifier := signing.NewVerifier(testKeys, signing.WithMaxSkew(time.Minute), signing.WithClock(func() time.Time { return now }))
+
+		_, err := verifier.Verify(newRequest(t, testKeyPair, now.Add(-2*time.Minute)))
+		assert.ErrorIs(t, err, signing.ErrStale)
+
+		_, err = verifier.Verify(newRequest(t, testKeyPair, now.Add(-30*time.Second)))
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,131 @@
+package integration
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+
+	"github.com/EnSync-engine/CLI/app/api"
+	"github.com/EnSync-engine/CLI/app/domain"
+)
+
+func TestTelemetry(t *testing.T) {
+	var (
+		mu           sync.Mutex
+		traceparents []string
+		fail         bool
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		traceparents = append(traceparents, r.Header.Get("traceparent"))
+		if fail {
+			fail = false
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.URL.Path == "/event/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		writeJSON(w, domain.Event{ID: "event-1", Name: "stripe"})
+	}))
+	defer server.Close()
+
+	spans := tracetest.NewSpanRecorder()
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))
+	reader := sdkmetric.NewManualReader()
+	meterProvider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	policy := api.DefaultRetryPolicy()
+	policy.WaitMin = time.Millisecond
+	policy.WaitMax = time.Millisecond
+	client := api.NewClient(server.URL, api.WithRetryPolicy(policy), api.WithTelemetry(tracerProvider, meterProvider))
+	client.SetAccessKey(testAccessKey)
+	ctx := context.Background()
+
+	fail = true
+	_, err := client.GetEventByName(ctx, "stripe")
+	require.NoError(t, err)
+	_, err = client.GetEventByName(ctx, "missing")
+	require.Error(t, err)
+
+	t.Run("SpanPerRequestWithRetryEvents", func(t *testing.T) {
+		ended := spans.Ended()
+		require.Len(t, ended, 2)
+
+		span := ended[0]
+		assert.Equal(t, "GET event", span.Name())
+		require.Len(t, span.Events(), 1)
+		assert.Equal(t, "retry", span.Events()[0].Name)
+		assert.Equal(t, codes.Unset, span.Status().Code)
+
+		assert.Equal(t, codes.Error, ended[1].Status().Code)
+	})
+
+	t.Run("PropagatesTraceparent", func(t *testing.T) {
+		require.Len(t, traceparents, 3)
+		traceID := spans.Ended()[0].SpanContext().TraceID().String()
+
+		assert.Contains(t, traceparents[0], traceID)
+		assert.Equal(t, traceparents[0], traceparents[1], "retries carry the same span")
+		assert.NotEqual(t, traceparents[1], traceparents[2])
+	})
+
+	t.Run("RecordsMetrics", func(t *testing.T) {
+		var metrics metricdata.ResourceMetrics
+		require.NoError(t, reader.Collect(ctx, &metrics))
+		require.Len(t, metrics.ScopeMetrics, 1)
+
+		byName := map[string]metricdata.Metrics{}
+		for _, m := range metrics.ScopeMetrics[0].Metrics {
+			byName[m.Name] = m
+		}
+
+		requests, ok := byName["ensync.client.requests"].Data.(metricdata.Sum[int64])
+		require.True(t, ok)
+		var total int64
+		for _, point := range requests.DataPoints {
+			total += point.Value
+		}
+		assert.Equal(t, int64(2), total)
+
+		duration, ok := byName["http.client.request.duration"].Data.(metricdata.Histogram[float64])
+		require.True(t, ok)
+		assert.Len(t, duration.DataPoints, 2, "one series per status")
+	})
+
+	t.Run("RedactsAccessKeys", func(t *testing.T) {
+		spans := tracetest.NewSpanRecorder()
+		tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))
+		client := api.NewClient(server.URL, api.WithTelemetry(tracerProvider, sdkmetric.NewMeterProvider()))
+		client.SetAccessKey(testAccessKey)
+		params := api.DefaultListParams()
+		params.Filter = map[string]string{"accessKey": "ak_raw_1"}
+
+		_, _ = client.GetAccessKeyPermissions(ctx, "ak_raw_1")
+		_, _ = client.ListAccessKeys(ctx, params)
+
+		ended := spans.Ended()
+		require.Len(t, ended, 2)
+		for _, span := range ended {
+			for _, attr := range span.Attributes() {
+				assert.NotContains(t, attr.Value.Emit(), "ak_raw_1", "attribute %s", attr.Key)
+				assert.NotContains(t, attr.Value.Emit(), testAccessKey, "attribute %s", attr.Key)
+			}
+		}
+		assert.Contains(t, ended[0].Attributes(), semconv.URLFull(server.URL+"/access-key/REDACTED/permissions"))
+	})
+}
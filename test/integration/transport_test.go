@@ -0,0 +1,189 @@
+package integration
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/EnSync-engine/CLI/app/api"
+	"github.com/EnSync-engine/CLI/app/domain"
+)
+
+func TestTLSOptions(t *testing.T) {
+	dir := t.TempDir()
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, domain.Event{ID: "event-1", Name: "stripe"})
+	})
+	ctx := context.Background()
+
+	newClient := func(t *testing.T, url string, opts api.TLSOptions) *api.Client {
+		tlsConfig, err := api.NewTLSConfig(opts)
+		require.NoError(t, err)
+		policy := api.DefaultRetryPolicy()
+		policy.MaxRetries = 0
+		client := api.NewClient(url, api.WithRetryPolicy(policy), api.WithTLSConfig(tlsConfig))
+		client.SetAccessKey(testAccessKey)
+		return client
+	}
+
+	server := httptest.NewTLSServer(handler)
+	defer server.Close()
+	caFile := filepath.Join(dir, "ca.pem")
+	writePEM(t, caFile, "CERTIFICATE", server.Certificate().Raw)
+
+	t.Run("CustomCA", func(t *testing.T) {
+		_, err := newClient(t, server.URL, api.TLSOptions{}).GetEventByName(ctx, "stripe")
+		require.Error(t, err, "the test server's CA is not trusted by default")
+
+		_, err = newClient(t, server.URL, api.TLSOptions{CAFile: caFile}).GetEventByName(ctx, "stripe")
+		assert.NoError(t, err)
+	})
+
+	t.Run("InsecureSkipVerify", func(t *testing.T) {
+		_, err := newClient(t, server.URL, api.TLSOptions{InsecureSkipVerify: true}).GetEventByName(ctx, "stripe")
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("MinVersion", func(t *testing.T) {
+		legacy := httptest.NewUnstartedServer(handler)
+		legacy.TLS = &tls.Config{MaxVersion: tls.VersionTLS12}
+		legacy.StartTLS()
+		defer legacy.Close()
+		legacyCA := filepath.Join(dir, "legacy-ca.pem")
+		writePEM(t, legacyCA, "CERTIFICATE", legacy.Certificate().Raw)
+
+		_, err := newClient(t, legacy.URL, api.TLSOptions{CAFile: legacyCA, MinVersion: tls.VersionTLS13}).GetEventByName(ctx, "stripe")
+		assert.Error(t, err)
+
+		_, err = newClient(t, legacy.URL, api.TLSOptions{CAFile: legacyCA, MinVersion: tls.VersionTLS12}).GetEventByName(ctx, "stripe")
+		assert.NoError(t, err)
+	})
+
+	t.Run("MutualTLS", func(t *testing.T) {
+		certFile, keyFile, clientCert := generateClientCert(t, dir)
+		clientCAs := x509.NewCertPool()
+		clientCAs.AddCert(clientCert)
+
+		gateway := httptest.NewUnstartedServer(handler)
+		gateway.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+		gateway.StartTLS()
+		defer gateway.Close()
+		gatewayCA := filepath.Join(dir, "gateway-ca.pem")
+		writePEM(t, gatewayCA, "CERTIFICATE", gateway.Certificate().Raw)
+
+		_, err := newClient(t, gateway.URL, api.TLSOptions{CAFile: gatewayCA}).GetEventByName(ctx, "stripe")
+		require.Error(t, err, "the gateway requires a client certificate")
+
+		_, err = newClient(t, gateway.URL, api.TLSOptions{CAFile: gatewayCA, CertFile: certFile, KeyFile: keyFile}).GetEventByName(ctx, "stripe")
+		assert.NoError(t, err)
+	})
+
+	t.Run("InvalidFiles", func(t *testing.T) {
+		_, err := api.NewTLSConfig(api.TLSOptions{CAFile: filepath.Join(dir, "missing.pem")})
+		assert.Error(t, err)
+
+		notPEM := filepath.Join(dir, "not.pem")
+		require.NoError(t, os.WriteFile(notPEM, []byte("hello"), 0o600))
+		_, err = api.NewTLSConfig(api.TLSOptions{CAFile: notPEM})
+		assert.Error(t, err)
+
+		_, err = api.NewTLSConfig(api.TLSOptions{CertFile: caFile})
+		assert.Error(t, err, "a certificate needs its key")
+	})
+}
+
+func TestProxy(t *testing.T) {
+	var proxiedHost string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxiedHost = r.Host
+		writeJSON(w, domain.Event{ID: "event-1", Name: "stripe"})
+	}))
+	defer proxy.Close()
+
+	t.Run("SendsRequestsThroughProxy", func(t *testing.T) {
+		proxyFunc, err := api.NewProxyFunc(proxy.URL, "")
+		require.NoError(t, err)
+		client := api.NewClient("http://ensync.internal", api.WithProxy(proxyFunc))
+		client.SetAccessKey(testAccessKey)
+
+		event, err := client.GetEventByName(context.Background(), "stripe")
+
+		require.NoError(t, err)
+		assert.Equal(t, "event-1", event.ID)
+		assert.Equal(t, "ensync.internal", proxiedHost)
+	})
+
+	t.Run("NoProxy", func(t *testing.T) {
+		proxyFunc, err := api.NewProxyFunc("socks5://proxy.internal:1080", ".internal,10.0.0.0/8")
+		require.NoError(t, err)
+
+		for host, proxied := range map[string]bool{
+			"https://ensync.internal/": false,
+			"https://10.1.2.3/":        false,
+			"https://ensync.example/":  true,
+		} {
+			req := httptest.NewRequest(http.MethodGet, host, nil)
+			proxyURL, err := proxyFunc(req)
+			require.NoError(t, err)
+			assert.Equal(t, proxied, proxyURL != nil, host)
+		}
+	})
+
+	t.Run("UnsupportedScheme", func(t *testing.T) {
+		_, err := api.NewProxyFunc("ftp://proxy.internal", "")
+
+		assert.Error(t, err)
+	})
+}
+
+func writePEM(t *testing.T, path, blockType string, der []byte) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600))
+}
+
+// generateClientCert writes a self-signed client certificate and its key
+// to dir.
+func generateClientCert(t *testing.T, dir string) (certFile, keyFile string, cert *x509.Certificate) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "ensync-cli"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err = x509.ParseCertificate(der)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile = filepath.Join(dir, "client.pem")
+	keyFile = filepath.Join(dir, "client-key.pem")
+	writePEM(t, certFile, "CERTIFICATE", der)
+	writePEM(t, keyFile, "EC PRIVATE KEY", keyDER)
+	return certFile, keyFile, cert
+}
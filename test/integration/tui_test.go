@@ -0,0 +1,186 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/EnSync-engine/CLI/app/api"
+	"github.com/EnSync-engine/CLI/app/domain"
+	"github.com/EnSync-engine/CLI/app/tui"
+)
+
+// tuiServer lists events, access keys and workspaces. Once reloaded is set,
+// the event list includes one more event.
+func tuiServer(t *testing.T, eventLists *atomic.Int32, reloaded *atomic.Bool) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/event":
+			eventLists.Add(1)
+			events := []*domain.Event{
+				{ID: "event-1", Name: "orders.created"},
+				{ID: "event-2", Name: "orders.shipped"},
+				{ID: "event-3", Name: "billing.paid"},
+			}
+			if reloaded.Load() {
+				events = append(events, &domain.Event{ID: "event-4", Name: "orders.cancelled"})
+			}
+			writeJSON(w, domain.EventList{ResultsLength: len(events), Results: events})
+		case "/access-key":
+			writeJSON(w, domain.AccessKeyList{ResultsLength: 1, Results: []*domain.AccessKeyPermissions{
+				{ID: "key-1", Name: "checkout service"},
+			}})
+		case "/workspace":
+			writeJSON(w, domain.WorkspaceList{ResultsLength: 1, Results: []*domain.Workspace{
+				{ID: "ws-1", Name: "gms", Path: "gms", Children: []*domain.Workspace{{ID: "ws-2", Name: "urbanhero", Path: "gms/urbanhero"}}},
+			}})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+// tuiDriver feeds messages to the model the way Bubble Tea does, running
+// the commands they return. Only the UI's own messages are fed back, so
+// timers such as the cursor blink never run.
+type tuiDriver struct {
+	t     *testing.T
+	model tea.Model
+}
+
+func (d *tuiDriver) send(msg tea.Msg) {
+	d.t.Helper()
+	var cmd tea.Cmd
+	d.model, cmd = d.model.Update(msg)
+	d.run(cmd)
+}
+
+func (d *tuiDriver) run(cmd tea.Cmd) {
+	if cmd == nil {
+		return
+	}
+	switch msg := cmd().(type) {
+	case tea.BatchMsg:
+		for _, cmd := range msg {
+			d.run(cmd)
+		}
+	default:
+		if strings.HasPrefix(fmt.Sprintf("%T", msg), "tui.") {
+			d.send(msg)
+		}
+	}
+}
+
+func (d *tuiDriver) key(keys ...tea.KeyMsg) {
+	for _, key := range keys {
+		d.send(key)
+	}
+}
+
+func (d *tuiDriver) typeText(s string) {
+	d.key(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)})
+}
+
+func newTUIDriver(t *testing.T) (*tuiDriver, *atomic.Int32, *atomic.Bool) {
+	var (
+		eventLists atomic.Int32
+		reloaded   atomic.Bool
+	)
+	server := tuiServer(t, &eventLists, &reloaded)
+	t.Cleanup(server.Close)
+	client := api.NewClient(server.URL)
+	client.SetAccessKey(testAccessKey)
+
+	d := &tuiDriver{t: t, model: tui.NewModel(context.Background(), client)}
+	d.run(d.model.Init())
+	d.send(tea.WindowSizeMsg{Width: 120, Height: 30})
+	return d, &eventLists, &reloaded
+}
+
+func TestTUI(t *testing.T) {
+	t.Run("SwitchesTabs", func(t *testing.T) {
+		d, _, _ := newTUIDriver(t)
+		assert.Contains(t, d.model.View(), "orders.created")
+
+		d.key(tea.KeyMsg{Type: tea.KeyTab})
+		assert.Contains(t, d.model.View(), "checkout service", "the access key tab loads when first shown")
+		assert.NotContains(t, d.model.View(), "orders.created")
+
+		d.key(tea.KeyMsg{Type: tea.KeyTab})
+		assert.Contains(t, d.model.View(), "gms/urbanhero", "nested workspaces are listed")
+
+		d.key(tea.KeyMsg{Type: tea.KeyTab})
+		assert.Contains(t, d.model.View(), "orders.created", "tabs wrap around")
+
+		d.key(tea.KeyMsg{Type: tea.KeyShiftTab})
+		assert.Contains(t, d.model.View(), "gms/urbanhero")
+	})
+
+	t.Run("Filters", func(t *testing.T) {
+		d, _, _ := newTUIDriver(t)
+
+		d.typeText("/")
+		d.typeText("billing")
+		view := d.model.View()
+		assert.Contains(t, view, "billing.paid")
+		assert.NotContains(t, view, "orders.created")
+
+		d.typeText("x")
+		assert.Contains(t, d.model.View(), "No results")
+
+		d.key(tea.KeyMsg{Type: tea.KeyEsc})
+		view = d.model.View()
+		assert.Contains(t, view, "orders.created", "esc clears the search")
+		assert.Contains(t, view, "billing.paid")
+	})
+
+	t.Run("FilterMatchesIDs", func(t *testing.T) {
+		d, _, _ := newTUIDriver(t)
+
+		d.typeText("/")
+		d.typeText("event-2")
+		d.key(tea.KeyMsg{Type: tea.KeyEnter})
+
+		view := d.model.View()
+		assert.Contains(t, view, "orders.shipped")
+		assert.NotContains(t, view, "orders.created")
+	})
+
+	t.Run("Reloads", func(t *testing.T) {
+		d, eventLists, reloaded := newTUIDriver(t)
+		require.Equal(t, int32(1), eventLists.Load())
+		d.key(tea.KeyMsg{Type: tea.KeyDown})
+
+		reloaded.Store(true)
+		d.key(tea.KeyMsg{Type: tea.KeyCtrlR})
+
+		assert.Equal(t, int32(2), eventLists.Load())
+		view := d.model.View()
+		assert.Contains(t, view, "orders.cancelled")
+		assert.Contains(t, view, `"id": "event-1"`, "the selection returns to the first row")
+	})
+
+	t.Run("DropsPagesRequestedBeforeReload", func(t *testing.T) {
+		d, eventLists, _ := newTUIDriver(t)
+
+		// The page requested by the first reload is still in flight when
+		// the second reload starts.
+		var pending tea.Cmd
+		d.model, pending = d.model.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+		d.key(tea.KeyMsg{Type: tea.KeyCtrlR})
+		d.run(pending)
+
+		assert.Equal(t, int32(3), eventLists.Load())
+		assert.Equal(t, 1, strings.Count(d.model.View(), "orders.shipped"), "rows are not duplicated")
+	})
+}
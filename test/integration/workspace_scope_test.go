@@ -0,0 +1,52 @@
+package integration
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/EnSync-engine/CLI/app/api"
+	"github.com/EnSync-engine/CLI/app/domain"
+)
+
+func TestWorkspaceScope(t *testing.T) {
+	var gotWorkspace string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotWorkspace = r.Header.Get("X-ENSYNC-WORKSPACE")
+		writeJSON(w, domain.EventList{
+			ResultsLength: 2,
+			Results: []*domain.Event{
+				{ID: "event-1", Name: "stripe"},
+				{ID: "event-2", Name: "paypal", Workspace: "gms/other"},
+			},
+		})
+	}))
+	defer server.Close()
+
+	client := api.NewClient(server.URL)
+	client.SetAccessKey(testAccessKey)
+	ctx := context.Background()
+
+	t.Run("Unscoped", func(t *testing.T) {
+		events, err := client.ListEvents(ctx, api.DefaultListParams())
+
+		require.NoError(t, err)
+		assert.Empty(t, gotWorkspace)
+		assert.Empty(t, events.Results[0].Workspace)
+	})
+
+	t.Run("Scoped", func(t *testing.T) {
+		client.SetWorkspace("gms/urbanhero")
+
+		events, err := client.ListEvents(ctx, api.DefaultListParams())
+
+		require.NoError(t, err)
+		assert.Equal(t, "gms/urbanhero", gotWorkspace)
+		assert.Empty(t, events.Results[0].Workspace, "only the workspace the server returns is shown")
+		assert.Equal(t, "gms/other", events.Results[1].Workspace)
+	})
+}
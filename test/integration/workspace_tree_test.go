@@ -0,0 +1,42 @@
+package integration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/EnSync-engine/CLI/app/domain"
+)
+
+func TestBuildWorkspaceTree(t *testing.T) {
+	t.Run("LinksFlatResultsByParentID", func(t *testing.T) {
+		workspaces := []*domain.Workspace{
+			{ID: "ws-3", Name: "stripe", Path: "gms/urbanhero/stripe", ParentID: "ws-2"},
+			{ID: "ws-1", Name: "gms", Path: "gms"},
+			{ID: "ws-2", Name: "urbanhero", Path: "gms/urbanhero", ParentID: "ws-1"},
+			{ID: "ws-4", Name: "orphan", Path: "missing/orphan", ParentID: "ws-missing"},
+		}
+
+		roots := domain.BuildWorkspaceTree(workspaces)
+
+		require.Len(t, roots, 2)
+		assert.Equal(t, "gms", roots[0].Name)
+		assert.Equal(t, "orphan", roots[1].Name)
+		require.Len(t, roots[0].Children, 1)
+		require.Len(t, roots[0].Children[0].Children, 1)
+		assert.Equal(t, "stripe", roots[0].Children[0].Children[0].Name)
+	})
+
+	t.Run("KeepsNestedResults", func(t *testing.T) {
+		child := &domain.Workspace{ID: "ws-2", Name: "urbanhero", Path: "gms/urbanhero", ParentID: "ws-1"}
+		workspaces := []*domain.Workspace{
+			{ID: "ws-1", Name: "gms", Path: "gms", Children: []*domain.Workspace{child}},
+		}
+
+		roots := domain.BuildWorkspaceTree(workspaces)
+
+		require.Len(t, roots, 1)
+		assert.Same(t, child, domain.FindByPath(roots, "gms/urbanhero"))
+	})
+}